@@ -35,6 +35,10 @@
 // is safe to leave the checksum set to zero; after a successful write, the
 // file is updated with the correct checksum value.
 //
+// To process large files without holding the whole file in memory, use a
+// [Reader] to read pages and cookies one at a time, and a [Writer] to write
+// pages as they are produced.
+//
 // # File format
 //
 // The binary file format has the following structure:
@@ -109,6 +113,8 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/creachadair/cookies"
@@ -144,6 +150,7 @@ type File struct {
 
 	// The cookie acceptance policy, as a binary-format property list.  If
 	// empty, a standard policy will be written for OnlyFromMainDocumentDomain.
+	// Use the AcceptPolicy and SetAcceptPolicy methods to access its value.
 	Policy []byte
 }
 
@@ -158,39 +165,120 @@ func (f *File) fixPages() {
 	f.Pages = pages
 }
 
+// PageOptions control how cookies are laid out into pages by Repaginate.
+// A nil *PageOptions is ready for use and places all the cookies in a
+// single page.
+type PageOptions struct {
+	// If true, cookies for each distinct domain are placed on separate pages,
+	// as Safari does. Domains are compared case-insensitively and without
+	// regard to a leading period, so "example.com" and ".example.com" share
+	// a page.
+	ByDomain bool
+
+	// If positive, at most this many cookies are placed on each page, and any
+	// excess spills over onto additional pages.
+	MaxPerPage int
+}
+
+func (o *PageOptions) byDomain() bool { return o != nil && o.ByDomain }
+
+func (o *PageOptions) maxPerPage() int {
+	if o == nil || o.MaxPerPage <= 0 {
+		return 0
+	}
+	return o.MaxPerPage
+}
+
+// Repaginate discards the existing page structure of f, sorts all its cookies
+// into a deterministic order, and repacks them into new pages according to
+// opts. Cookies are ordered by domain, then name, then path, then creation
+// time. Any pages left empty are dropped.
+func (f *File) Repaginate(opts *PageOptions) {
+	var all []*Cookie
+	for _, page := range f.Pages {
+		all = append(all, page.Cookies...)
+	}
+	slices.SortStableFunc(all, compareCookies)
+
+	var pages []*Page
+	var cur *Page
+	limit := opts.maxPerPage()
+	for i, c := range all {
+		newPage := cur == nil || (limit > 0 && len(cur.Cookies) == limit)
+		if opts.byDomain() && i > 0 && domainKey(all[i-1].URL) != domainKey(c.URL) {
+			newPage = true
+		}
+		if newPage {
+			cur = new(Page)
+			pages = append(pages, cur)
+		}
+		cur.Cookies = append(cur.Cookies, c)
+	}
+	f.Pages = pages
+}
+
+// compareCookies defines the canonical ordering of cookies for Repaginate.
+func compareCookies(a, b *Cookie) int {
+	if v := strings.Compare(domainKey(a.URL), domainKey(b.URL)); v != 0 {
+		return v
+	} else if v := strings.Compare(a.URL, b.URL); v != 0 {
+		return v
+	} else if v := strings.Compare(a.Name, b.Name); v != 0 {
+		return v
+	} else if v := strings.Compare(a.Path, b.Path); v != 0 {
+		return v
+	}
+	return a.Created.Compare(b.Created)
+}
+
+// domainKey normalizes a cookie domain for grouping and comparison.
+func domainKey(url string) string { return strings.ToLower(strings.TrimPrefix(url, ".")) }
+
 // WriteTo encodes f in binary format to w.
+//
+// Pages are encoded and written one at a time, so the memory needed to write
+// the file is proportional to the size of the largest page.
 func (f *File) WriteTo(w io.Writer) (int64, error) {
 	f.fixPages()
+	cw := &countWriter{w: w}
 
 	var buf bytes.Buffer
 	buf.WriteString(fileMagic)
 	writeBig32(&buf, uint32(len(f.Pages)))
-	pos := buf.Len()                       // position of next length
-	addPadding(&buf, "xxxx", len(f.Pages)) // length placeholders
+	for _, page := range f.Pages {
+		writeBig32(&buf, uint32(page.size()))
+	}
+	if _, err := buf.WriteTo(cw); err != nil {
+		return cw.n, err
+	}
 
 	var checksum uint32
 	for _, page := range f.Pages {
-		nw, err := page.WriteTo(&buf)
-		if err != nil {
-			return 0, err
+		if _, err := page.WriteTo(&buf); err != nil {
+			return cw.n, err
+		}
+		checksum += pageChecksum(buf.Bytes())
+		if _, err := buf.WriteTo(cw); err != nil {
+			return cw.n, err
 		}
-		data := buf.Bytes()
-		checksum += pageChecksum(data[len(data)-int(nw):])
-		binary.BigEndian.PutUint32(data[pos:], uint32(nw)) // update length
-		pos += 4
 	}
 
 	f.Checksum = checksum
-	writeBig32(&buf, checksum)
-	buf.WriteString(fileTrailer)
+	writeFooter(&buf, checksum, f.Policy)
+	_, err := buf.WriteTo(cw)
+	return cw.n, err
+}
 
-	p := f.Policy
-	if len(p) == 0 {
-		p = []byte(DefaultPolicy)
+// writeFooter writes the file checksum, trailer, and cookie accept policy to
+// buf. If policy is empty, DefaultPolicy is used.
+func writeFooter(buf *bytes.Buffer, checksum uint32, policy []byte) {
+	writeBig32(buf, checksum)
+	buf.WriteString(fileTrailer)
+	if len(policy) == 0 {
+		policy = []byte(DefaultPolicy)
 	}
-	writeBig32(&buf, uint32(len(p)))
-	buf.Write(p)
-	return io.Copy(w, &buf)
+	writeBig32(buf, uint32(len(policy)))
+	buf.Write(policy)
 }
 
 // A Page is a collection of cookies.
@@ -198,6 +286,15 @@ type Page struct {
 	Cookies []*Cookie
 }
 
+// size reports the length in bytes of the binary encoding of p.
+func (p *Page) size() int {
+	n := len(pageMagic) + 4 + 4*len(p.Cookies) + 4 // magic, count, offsets, trailer
+	for _, c := range p.Cookies {
+		n += c.size()
+	}
+	return n
+}
+
 // WriteTo encodes p in binary format to w.
 func (p *Page) WriteTo(w io.Writer) (int64, error) {
 	var buf bytes.Buffer
@@ -221,6 +318,10 @@ func (p *Page) WriteTo(w io.Writer) (int64, error) {
 }
 
 // A Cookie represents a single cookie.
+//
+// A Cookie also satisfies [cookies.AttrEditor], with the following attributes:
+//
+//	flags -- uint32, the raw flag bits of the cookie (see FlagSecure, etc.)
 type Cookie struct {
 	Flags   uint32
 	URL     string
@@ -237,24 +338,32 @@ type Cookie struct {
 // Get returns a format-independent representation of c.
 // It satisfies part of [cookies.Editor].
 func (c *Cookie) Get() cookies.C {
+	domain, hostOnly := cookies.ParseHostKey(c.URL)
 	return cookies.C{
 		Name:    c.Name,
 		Value:   c.Value,
-		Domain:  c.URL,
+		Domain:  domain,
 		Path:    c.Path,
 		Expires: c.Expires,
 		Created: c.Created,
 		Flags: cookies.Flags{
 			Secure:   c.Flags&FlagSecure != 0,
 			HTTPOnly: c.Flags&FlagHTTPOnly != 0,
+			HostOnly: hostOnly,
 		},
 		SameSite: c.sameSite(),
 	}
 }
 
-// Set updates c to match the contents of o.
+// Set updates c to match the contents of o.  It reports an error if o is not
+// valid according to [cookies.Validate]. The format adds no limits of its
+// own: Its strings are NUL-terminated, which Validate already excludes, and
+// its 32-bit sizes and offsets exceed the lengths Validate permits.
 // It satisfies part of [cookies.Editor].
 func (c *Cookie) Set(o cookies.C) error {
+	if err := cookies.Validate(o); err != nil {
+		return err
+	}
 	f := c.Flags &^ FlagFlagsMask
 	if o.Flags.Secure {
 		f |= FlagSecure
@@ -263,7 +372,7 @@ func (c *Cookie) Set(o cookies.C) error {
 		f |= FlagHTTPOnly
 	}
 	c.Flags = f
-	c.URL = o.Domain
+	c.URL = o.HostKey()
 	c.Name = o.Name
 	c.Path = o.Path
 	c.Value = o.Value
@@ -273,6 +382,36 @@ func (c *Cookie) Set(o cookies.C) error {
 	return nil
 }
 
+// Attrs returns the format-specific attributes of c.
+// It satisfies part of [cookies.AttrEditor].
+func (c *Cookie) Attrs() map[string]any { return map[string]any{"flags": c.Flags} }
+
+// SetAttr sets the named format-specific attribute of c.
+// It satisfies part of [cookies.AttrEditor].
+func (c *Cookie) SetAttr(name string, value any) error {
+	switch name {
+	case "flags":
+		v, ok := value.(uint32)
+		if !ok {
+			return fmt.Errorf("attribute %q: got %T, want uint32", name, value)
+		}
+		c.Flags = v
+		return nil
+	default:
+		return fmt.Errorf("unknown attribute %q", name)
+	}
+}
+
+// cookieHeaderSize is the size in bytes of the fixed-length portion of the
+// binary encoding of a cookie.
+const cookieHeaderSize = 56
+
+// size reports the length in bytes of the binary encoding of c.
+func (c *Cookie) size() int {
+	// Each string is followed by a NUL terminator.
+	return cookieHeaderSize + len(c.URL) + len(c.Name) + len(c.Path) + len(c.Value) + 4
+}
+
 // WriteTo encodes c in binary format to w.
 func (c *Cookie) WriteTo(w io.Writer) (int64, error) {
 	var buf bytes.Buffer
@@ -373,34 +512,48 @@ func ParseFile(data []byte) (*File, error) {
 		cur += size
 	}
 
+	fcheck, policy, err := parseFooter(data[cur:])
+	if err != nil {
+		return nil, err
+	}
+	return &File{
+		Pages:    pages,
+		Checksum: fcheck,
+		Policy:   policy,
+	}, nil
+}
+
+// parseFooter parses the checksum, trailer, and cookie accept policy that
+// follow the last page of a file.
+func parseFooter(data []byte) (uint32, []byte, error) {
 	// Checksum.
-	fcheck, err := bigUint32(data, cur)
+	fcheck, err := bigUint32(data, 0)
 	if err != nil {
-		return nil, fmt.Errorf("invalid file checksum: %w", err)
+		return 0, nil, fmt.Errorf("invalid file checksum: %w", err)
 	}
-	cur += 4
+	cur := 4
 
 	// File trailer. Not sure what this is, maybe a version?
 	if !bytes.HasPrefix(data[cur:], []byte(fileTrailer)) {
-		return nil, errors.New("invalid file trailer")
+		return 0, nil, errors.New("invalid file trailer")
 	}
 	cur += len(fileTrailer)
 
-	f := &File{
-		Pages:    pages,
-		Checksum: fcheck,
-	}
+	var policy []byte
 	if cur < len(data) {
 		// Cookie accept policy, encoded as a binary property list.
 		plen, err := bigUint32(data, cur)
 		if err != nil {
-			return nil, err
+			return 0, nil, err
 		}
 		cur += 4
 		end := cur + int(plen)
-		f.Policy = data[cur:end]
+		if end > len(data) {
+			return 0, nil, fmt.Errorf("policy truncated at %d", len(data))
+		}
+		policy = data[cur:end]
 	}
-	return f, nil
+	return fcheck, policy, nil
 }
 
 func parsePage(data []byte) (*Page, error) {
@@ -555,6 +708,18 @@ func writeFloat64(w io.Writer, f float64) {
 	w.Write(buf[:])
 }
 
+// countWriter wraps an io.Writer and counts the bytes written to it.
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(data []byte) (int, error) {
+	nw, err := c.w.Write(data)
+	c.n += int64(nw)
+	return nw, err
+}
+
 // addPadding extends buf with n copies of s.
 func addPadding(buf *bytes.Buffer, s string, n int) {
 	buf.Grow(n * len(s))
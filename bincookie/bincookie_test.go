@@ -16,10 +16,14 @@ package bincookie_test
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
 	"time"
 
@@ -150,6 +154,347 @@ func TestRoundTrip(t *testing.T) {
 	}
 }
 
+func TestRepaginate(t *testing.T) {
+	newFile := func() *bincookie.File {
+		return &bincookie.File{
+			Pages: []*bincookie.Page{{
+				Cookies: []*bincookie.Cookie{
+					{URL: "b.org", Name: "z"},
+					{URL: ".a.com", Name: "y"},
+					{URL: "b.org", Name: "x"},
+				},
+			}, {
+				Cookies: []*bincookie.Cookie{
+					{URL: "a.com", Name: "w"},
+					{URL: "C.net", Name: "v"},
+				},
+			}},
+		}
+	}
+
+	// layout renders the page structure of f as a list of lists.
+	layout := func(f *bincookie.File) [][]string {
+		var out [][]string
+		for _, p := range f.Pages {
+			var names []string
+			for _, c := range p.Cookies {
+				names = append(names, c.URL+":"+c.Name)
+			}
+			out = append(out, names)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name string
+		opts *bincookie.PageOptions
+		want [][]string
+	}{
+		{"Default", nil, [][]string{
+			{".a.com:y", "a.com:w", "b.org:x", "b.org:z", "C.net:v"},
+		}},
+		{"ByDomain", &bincookie.PageOptions{ByDomain: true}, [][]string{
+			{".a.com:y", "a.com:w"}, {"b.org:x", "b.org:z"}, {"C.net:v"},
+		}},
+		{"MaxPerPage", &bincookie.PageOptions{MaxPerPage: 2}, [][]string{
+			{".a.com:y", "a.com:w"}, {"b.org:x", "b.org:z"}, {"C.net:v"},
+		}},
+		{"Both", &bincookie.PageOptions{ByDomain: true, MaxPerPage: 1}, [][]string{
+			{".a.com:y"}, {"a.com:w"}, {"b.org:x"}, {"b.org:z"}, {"C.net:v"},
+		}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newFile()
+			f.Repaginate(tc.opts)
+			if diff := cmp.Diff(tc.want, layout(f)); diff != "" {
+				t.Errorf("Layout: (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStream(t *testing.T) {
+	base := time.Unix(1602034364, 0).UTC()
+	f := &bincookie.File{
+		Pages: []*bincookie.Page{{
+			Cookies: []*bincookie.Cookie{
+				{URL: "a.com", Name: "x", Value: "1", Created: base, Expires: base},
+				{URL: "a.com", Name: "y", Value: "2", Created: base, Expires: base},
+			},
+		}, {
+			Cookies: []*bincookie.Cookie{
+				{URL: "b.org", Name: "z", Value: "3", Created: base, Expires: base},
+			},
+		}},
+	}
+	var want bytes.Buffer
+	if _, err := f.WriteTo(&want); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	t.Run("Reader", func(t *testing.T) {
+		r, err := bincookie.NewReader(bytes.NewReader(want.Bytes()))
+		if err != nil {
+			t.Fatalf("NewReader failed: %v", err)
+		}
+		if got := r.NumPages(); got != len(f.Pages) {
+			t.Errorf("NumPages: got %d, want %d", got, len(f.Pages))
+		}
+		var got []*bincookie.Cookie
+		for {
+			c, err := r.Next()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("Next failed: %v", err)
+			}
+			got = append(got, c)
+		}
+		wantCookies := append(f.Pages[0].Cookies, f.Pages[1].Cookies...)
+		opt := cmpopts.IgnoreUnexported(bincookie.Cookie{})
+		if diff := cmp.Diff(wantCookies, got, opt); diff != "" {
+			t.Errorf("Cookies: (-want, +got)\n%s", diff)
+		}
+
+		sum, policy, err := r.Footer()
+		if err != nil {
+			t.Fatalf("Footer failed: %v", err)
+		}
+		if sum != f.Checksum {
+			t.Errorf("Checksum: got %04x, want %04x", sum, f.Checksum)
+		}
+		if string(policy) != bincookie.DefaultPolicy {
+			t.Errorf("Policy: got %q, want %q", policy, bincookie.DefaultPolicy)
+		}
+	})
+
+	t.Run("Writer", func(t *testing.T) {
+		out, err := os.Create(filepath.Join(t.TempDir(), "test.binarycookies"))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		defer out.Close()
+
+		w := bincookie.NewWriter(out, len(f.Pages))
+		for _, p := range f.Pages {
+			if err := w.WritePage(p); err != nil {
+				t.Fatalf("WritePage failed: %v", err)
+			}
+		}
+		if err := w.WritePage(f.Pages[0]); err == nil {
+			t.Error("WritePage of extra page: got nil, want error")
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+		got, err := os.ReadFile(out.Name())
+		if err != nil {
+			t.Fatalf("Reading output: %v", err)
+		}
+		if diff := cmp.Diff(want.Bytes(), got); diff != "" {
+			t.Errorf("Output: (-want, +got)\n%s", diff)
+		}
+	})
+}
+
+func TestStreamHugeSizes(t *testing.T) {
+	// allocated reports the number of bytes allocated while running f.
+	allocated := func(f func()) uint64 {
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		f()
+		runtime.ReadMemStats(&after)
+		return after.TotalAlloc - before.TotalAlloc
+	}
+	const maxAlloc = 1 << 20
+
+	// A file declaring one page of nearly 4 GiB, with no page data.
+	page := []byte("cook\x00\x00\x00\x01\xff\xff\xff\xff")
+	r, err := bincookie.NewReader(bytes.NewReader(page))
+	if err != nil {
+		t.Fatalf("NewReader failed: %v", err)
+	}
+	if n := allocated(func() {
+		if _, err := r.NextPage(); err == nil {
+			t.Error("NextPage: got nil, want error")
+		}
+	}); n > maxAlloc {
+		t.Errorf("NextPage allocated %d bytes, want at most %d", n, maxAlloc)
+	}
+
+	// A file with no pages, whose footer declares a policy of nearly 4 GiB.
+	footer := []byte("cook\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xff\xff\xff\xff")
+	r, err = bincookie.NewReader(bytes.NewReader(footer))
+	if err != nil {
+		t.Fatalf("NewReader failed: %v", err)
+	}
+	if n := allocated(func() {
+		if _, _, err := r.Footer(); err == nil {
+			t.Error("Footer: got nil, want error")
+		}
+	}); n > maxAlloc {
+		t.Errorf("Footer allocated %d bytes, want at most %d", n, maxAlloc)
+	}
+}
+
+func TestAcceptPolicy(t *testing.T) {
+	var f bincookie.File
+	if p, err := f.AcceptPolicy(); err != nil {
+		t.Fatalf("AcceptPolicy failed: %v", err)
+	} else if p != bincookie.AcceptOnlyFromMainDocumentDomain {
+		t.Errorf("Default policy: got %v, want %v", p, bincookie.AcceptOnlyFromMainDocumentDomain)
+	}
+
+	if err := f.SetAcceptPolicy(bincookie.AcceptNever); err != nil {
+		t.Fatalf("SetAcceptPolicy failed: %v", err)
+	}
+
+	// The updated policy should survive a round trip through the file.
+	var buf bytes.Buffer
+	if _, err := f.WriteTo(&buf); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	g, err := bincookie.ParseFile(buf.Bytes())
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	if p, err := g.AcceptPolicy(); err != nil {
+		t.Fatalf("AcceptPolicy failed: %v", err)
+	} else if p != bincookie.AcceptNever {
+		t.Errorf("Updated policy: got %v, want %v", p, bincookie.AcceptNever)
+	}
+
+	// Restoring the default should reproduce the standard encoding.
+	if err := g.SetAcceptPolicy(bincookie.AcceptOnlyFromMainDocumentDomain); err != nil {
+		t.Fatalf("SetAcceptPolicy failed: %v", err)
+	}
+	if got := string(g.Policy); got != bincookie.DefaultPolicy {
+		t.Errorf("Policy: got %q, want %q", got, bincookie.DefaultPolicy)
+	}
+}
+
+func TestKeyed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Cookies.binarycookies")
+	var empty bincookie.File
+	var buf bytes.Buffer
+	if _, err := empty.WriteTo(&buf); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		t.Fatalf("Write file: %v", err)
+	}
+	s, err := bincookie.Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	var _ cookies.KeyedStore = s
+
+	now := time.Now().Truncate(time.Second)
+	a := cookies.C{Name: "a", Value: "1", Domain: "example.com", Path: "/", Expires: now.Add(time.Hour), Created: now}
+	b := cookies.C{Name: "b", Value: "2", Domain: "example.com", Path: "/", Expires: now.Add(time.Hour), Created: now}
+	if _, err := s.Get(a.Key()); err != cookies.ErrNotFound {
+		t.Errorf("Get %v: got %v, want %v", a.Key(), err, cookies.ErrNotFound)
+	}
+	for _, c := range []cookies.C{a, b} {
+		if err := s.Put(c); err != nil {
+			t.Fatalf("Put %v: %v", c.Key(), err)
+		}
+	}
+	a.Value = "updated"
+	if err := s.Put(a); err != nil {
+		t.Fatalf("Put %v: %v", a.Key(), err)
+	}
+	if err := s.Delete(b.Key()); err != nil {
+		t.Fatalf("Delete %v: %v", b.Key(), err)
+	}
+	if err := s.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	// Reopen the file to verify that the changes were persisted.
+	s, err = bincookie.Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	got, err := s.Get(a.Key())
+	if err != nil {
+		t.Fatalf("Get %v: %v", a.Key(), err)
+	}
+	if diff := cmp.Diff(a, got); diff != "" {
+		t.Errorf("Get %v: (-want, +got)\n%s", a.Key(), diff)
+	}
+	if _, err := s.Get(b.Key()); err != cookies.ErrNotFound {
+		t.Errorf("Get %v: got %v, want %v", b.Key(), err, cookies.ErrNotFound)
+	}
+}
+
+func TestScanContext(t *testing.T) {
+	f := &bincookie.File{Pages: []*bincookie.Page{
+		{Cookies: []*bincookie.Cookie{{Name: "a", URL: "a.com"}, {Name: "b", URL: "a.com"}}},
+		{Cookies: []*bincookie.Cookie{{Name: "c", URL: "c.com"}}},
+	}}
+	path := filepath.Join(t.TempDir(), "Cookies.binarycookies")
+	var buf bytes.Buffer
+	if _, err := f.WriteTo(&buf); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		t.Fatalf("Write file: %v", err)
+	}
+	s, err := bincookie.Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	var _ cookies.ContextStore = s
+
+	// Cancellation takes effect at the next page boundary.
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	var got []string
+	err = s.ScanContext(ctx, func(e cookies.Editor) (cookies.Action, error) {
+		got = append(got, e.Get().Name)
+		cancel()
+		return cookies.Keep, nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("ScanContext: got error %v, want %v", err, context.Canceled)
+	}
+	if diff := cmp.Diff([]string{"a", "b"}, got); diff != "" {
+		t.Errorf("Visited (-want, +got):\n%s", diff)
+	}
+}
+
+func TestAttrs(t *testing.T) {
+	c := &bincookie.Cookie{Flags: bincookie.FlagSecure}
+	var _ cookies.AttrEditor = c
+
+	if diff := cmp.Diff(map[string]any{"flags": uint32(bincookie.FlagSecure)}, c.Attrs()); diff != "" {
+		t.Errorf("Attrs (-want, +got):\n%s", diff)
+	}
+	if err := c.SetAttr("flags", 5); err == nil {
+		t.Error("SetAttr wrong type: got nil, want error")
+	}
+	if err := c.SetAttr("flags", uint32(bincookie.FlagHTTPOnly|bincookie.FlagSameSiteLax)); err != nil {
+		t.Fatalf("SetAttr: unexpected error: %v", err)
+	}
+	got := c.Get()
+	if got.Flags.Secure || !got.Flags.HTTPOnly || got.SameSite != cookies.Lax {
+		t.Errorf("After SetAttr: got %+v, want HTTPOnly, SameSite=Lax", got)
+	}
+}
+
+func TestSetInvalid(t *testing.T) {
+	c := &bincookie.Cookie{Name: "ok", URL: "example.com", Path: "/"}
+	err := c.Set(cookies.C{Name: "bad\x00name", Domain: "example.com", Path: "/"})
+	if !errors.Is(err, cookies.ErrInvalid) {
+		t.Errorf("Set: got %v, want %v", err, cookies.ErrInvalid)
+	}
+	if c.Name != "ok" {
+		t.Errorf("After failed Set: name is %q, want %q", c.Name, "ok")
+	}
+}
+
 func trimValue(s string) string {
 	if len(s) < 70 {
 		return s
@@ -0,0 +1,517 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bplist implements a minimal encoder and decoder for Apple binary
+// property lists ("bplist00").
+//
+// Only the subset of the format needed for cookie files is supported. Values
+// are mapped to and from Go types as follows:
+//
+//	Property list | Go type
+//	--------------|-----------------
+//	null          | nil
+//	boolean       | bool
+//	integer       | int64
+//	real          | float64
+//	string        | string
+//	data          | []byte
+//	array         | []any
+//	dictionary    | map[string]any
+//
+// Dictionaries with non-string keys, dates, UIDs, and sets are not supported,
+// nor are unsigned integers greater than [math.MaxInt64]. An object referenced
+// more than once is decoded only once, and its references share the value.
+//
+// # Format
+//
+// A binary property list has the following structure:
+//
+//	 Bytes | Format     | Description
+//	-------|------------|----------------------------------------------
+//	 8     | text       | magic number ('bplist00')
+//	 ...   | objects    | encoded objects, referenced by index
+//	 ...   | offsets    | object offsets, each os bytes BE
+//	 6     | bytes      | unused; zero
+//	 1     | uint8      | offset size in bytes (os)
+//	 1     | uint8      | object reference size in bytes
+//	 8     | uint64 BE  | number of objects
+//	 8     | uint64 BE  | index of the top-level object
+//	 8     | uint64 BE  | offset of the offset table
+package bplist
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"unicode/utf16"
+)
+
+const (
+	magic       = "bplist00"
+	trailerSize = 32
+
+	// The maximum nesting depth accepted by the decoder.
+	maxDepth = 64
+)
+
+// Object type markers (high-order 4 bits of the marker byte).
+const (
+	tagSimple = 0x00 // null, false, true
+	tagInt    = 0x10
+	tagReal   = 0x20
+	tagData   = 0x40
+	tagASCII  = 0x50
+	tagUTF16  = 0x60
+	tagArray  = 0xa0
+	tagDict   = 0xd0
+)
+
+// Decode decodes a binary property list and returns its top-level object.
+func Decode(data []byte) (any, error) {
+	if !bytes.HasPrefix(data, []byte(magic)) {
+		return nil, errors.New("invalid plist magic")
+	}
+	if len(data) < len(magic)+trailerSize {
+		return nil, errors.New("plist truncated")
+	}
+	t := data[len(data)-trailerSize:]
+	d := &decoder{
+		data:    data,
+		offSize: int(t[6]),
+		refSize: int(t[7]),
+		cache:   make(map[uint64]any),
+	}
+	numObjects := binary.BigEndian.Uint64(t[8:])
+	top := binary.BigEndian.Uint64(t[16:])
+	tableOff := binary.BigEndian.Uint64(t[24:])
+	if d.offSize < 1 || d.offSize > 8 || d.refSize < 1 || d.refSize > 8 {
+		return nil, errors.New("invalid plist trailer")
+	}
+	tableEnd := uint64(len(data) - trailerSize)
+	if tableOff > tableEnd || numObjects > (tableEnd-tableOff)/uint64(d.offSize) {
+		return nil, errors.New("invalid plist offset table")
+	} else if top >= numObjects {
+		return nil, fmt.Errorf("invalid top object %d", top)
+	}
+	for i := range numObjects {
+		pos := int(tableOff) + int(i)*d.offSize
+		d.offsets = append(d.offsets, readUint(data[pos:pos+d.offSize]))
+	}
+	return d.object(top, 0)
+}
+
+type decoder struct {
+	data    []byte
+	offSize int
+	refSize int
+	offsets []uint64
+	cache   map[uint64]any // decoded objects, by index
+}
+
+// object decodes the object with index ref, at nesting depth, or returns the
+// value already decoded for ref. Without the cache, a small list whose arrays
+// refer repeatedly to the same children could take exponential time.
+func (d *decoder) object(ref uint64, depth int) (any, error) {
+	if v, ok := d.cache[ref]; ok {
+		return v, nil
+	}
+	v, err := d.decode(ref, depth)
+	if err != nil {
+		return nil, err
+	}
+	d.cache[ref] = v
+	return v, nil
+}
+
+// decode decodes the object with index ref, at nesting depth.
+func (d *decoder) decode(ref uint64, depth int) (any, error) {
+	if depth > maxDepth {
+		return nil, errors.New("plist nesting too deep")
+	} else if ref >= uint64(len(d.offsets)) {
+		return nil, fmt.Errorf("invalid object reference %d", ref)
+	}
+	pos := d.offsets[ref]
+	if pos < uint64(len(magic)) || pos >= uint64(len(d.data)-trailerSize) {
+		return nil, fmt.Errorf("object %d: invalid offset %d", ref, pos)
+	}
+	cur := int(pos)
+	marker := d.data[cur]
+	tag, info := marker&0xf0, int(marker&0x0f)
+	cur++
+
+	switch tag {
+	case tagSimple:
+		switch marker {
+		case 0x00:
+			return nil, nil
+		case 0x08:
+			return false, nil
+		case 0x09:
+			return true, nil
+		}
+
+	case tagInt:
+		v, _, err := d.readInt(cur, info)
+		return v, err
+
+	case tagReal:
+		switch info {
+		case 2:
+			b, err := d.bytes(cur, 4)
+			if err != nil {
+				return nil, err
+			}
+			return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), nil
+		case 3:
+			b, err := d.bytes(cur, 8)
+			if err != nil {
+				return nil, err
+			}
+			return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+		}
+
+	case tagData, tagASCII, tagUTF16, tagArray, tagDict:
+		n, cur, err := d.count(cur, info)
+		if err != nil {
+			return nil, fmt.Errorf("object %d: %w", ref, err)
+		}
+		switch tag {
+		case tagData:
+			b, err := d.bytes(cur, n)
+			return bytes.Clone(b), err
+		case tagASCII:
+			b, err := d.bytes(cur, n)
+			return string(b), err
+		case tagUTF16:
+			b, err := d.bytes(cur, 2*n)
+			if err != nil {
+				return nil, err
+			}
+			u := make([]uint16, n)
+			for i := range u {
+				u[i] = binary.BigEndian.Uint16(b[2*i:])
+			}
+			return string(utf16.Decode(u)), nil
+		case tagArray:
+			refs, err := d.refs(cur, n)
+			if err != nil {
+				return nil, err
+			}
+			out := make([]any, n)
+			for i, r := range refs {
+				out[i], err = d.object(r, depth+1)
+				if err != nil {
+					return nil, err
+				}
+			}
+			return out, nil
+		case tagDict:
+			refs, err := d.refs(cur, 2*n)
+			if err != nil {
+				return nil, err
+			}
+			out := make(map[string]any, n)
+			for i := range n {
+				k, err := d.object(refs[i], depth+1)
+				if err != nil {
+					return nil, err
+				}
+				key, ok := k.(string)
+				if !ok {
+					return nil, fmt.Errorf("object %d: dictionary key is %T, not string", ref, k)
+				}
+				out[key], err = d.object(refs[n+i], depth+1)
+				if err != nil {
+					return nil, err
+				}
+			}
+			return out, nil
+		}
+	}
+	return nil, fmt.Errorf("object %d: unsupported marker %02x", ref, marker)
+}
+
+// bytes returns n bytes of data starting at pos.
+func (d *decoder) bytes(pos, n int) ([]byte, error) {
+	if n < 0 || pos+n > len(d.data)-trailerSize {
+		return nil, fmt.Errorf("incomplete object at %d", pos)
+	}
+	return d.data[pos : pos+n], nil
+}
+
+// readInt reads an integer of 1<<logSize bytes at pos, and returns its value
+// and the position following it.
+func (d *decoder) readInt(pos, logSize int) (int64, int, error) {
+	if logSize > 4 {
+		return 0, 0, fmt.Errorf("invalid integer size %d", 1<<logSize)
+	}
+	n := 1 << logSize
+	b, err := d.bytes(pos, n)
+	if err != nil {
+		return 0, 0, err
+	}
+	if n == 16 {
+		b = b[8:] // 128-bit values; keep the low-order 64 bits
+	}
+	return int64(readUint(b)), pos + n, nil
+}
+
+// count decodes the length of a variable-sized object whose marker has the
+// given low-order bits, and returns the length and the position of the data
+// following it.
+func (d *decoder) count(pos, info int) (int, int, error) {
+	if info != 0x0f {
+		return info, pos, nil
+	}
+	b, err := d.bytes(pos, 1)
+	if err != nil {
+		return 0, 0, err
+	} else if b[0]&0xf0 != tagInt {
+		return 0, 0, errors.New("invalid length marker")
+	}
+	v, next, err := d.readInt(pos+1, int(b[0]&0x0f))
+	if err != nil {
+		return 0, 0, err
+	} else if v < 0 || v > int64(len(d.data)) {
+		return 0, 0, fmt.Errorf("invalid length %d", v)
+	}
+	return int(v), next, nil
+}
+
+// refs reads n object references starting at pos.
+func (d *decoder) refs(pos, n int) ([]uint64, error) {
+	b, err := d.bytes(pos, n*d.refSize)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]uint64, n)
+	for i := range out {
+		out[i] = readUint(b[i*d.refSize : (i+1)*d.refSize])
+	}
+	return out, nil
+}
+
+// Encode encodes v as a binary property list. The types of v and any values
+// it contains must be among those listed in the package documentation.  Any
+// signed or unsigned integer type is accepted and encoded as an integer.
+// Dictionary keys are written in lexicographic order.
+func Encode(v any) ([]byte, error) {
+	var e encoder
+	if _, err := e.flatten(v); err != nil {
+		return nil, err
+	}
+	refSize := sizeFor(uint64(len(e.objs)))
+
+	var buf bytes.Buffer
+	buf.WriteString(magic)
+	offsets := make([]uint64, len(e.objs))
+	for i, obj := range e.objs {
+		offsets[i] = uint64(buf.Len())
+		obj.writeTo(&buf, refSize)
+	}
+	tableOff := uint64(buf.Len())
+	offSize := sizeFor(tableOff)
+	for _, off := range offsets {
+		writeUint(&buf, off, offSize)
+	}
+
+	var t [trailerSize]byte
+	t[6] = byte(offSize)
+	t[7] = byte(refSize)
+	binary.BigEndian.PutUint64(t[8:], uint64(len(e.objs)))
+	binary.BigEndian.PutUint64(t[16:], 0) // the top object is always first
+	binary.BigEndian.PutUint64(t[24:], tableOff)
+	buf.Write(t[:])
+	return buf.Bytes(), nil
+}
+
+type encoder struct {
+	objs []*node
+}
+
+// A node is a single object in the flattened object table.
+type node struct {
+	marker byte
+	data   []byte   // scalar content, including any length prefix
+	refs   []uint64 // container contents
+}
+
+func (n *node) writeTo(buf *bytes.Buffer, refSize int) {
+	buf.WriteByte(n.marker)
+	buf.Write(n.data)
+	for _, r := range n.refs {
+		writeUint(buf, r, refSize)
+	}
+}
+
+// flatten adds v and any values it contains to the object table, and returns
+// the index of v in the table.
+func (e *encoder) flatten(v any) (uint64, error) {
+	idx := uint64(len(e.objs))
+	n := new(node)
+	e.objs = append(e.objs, n)
+
+	switch t := v.(type) {
+	case nil:
+		n.marker = 0x00
+	case bool:
+		n.marker = 0x08
+		if t {
+			n.marker = 0x09
+		}
+	case int:
+		n.marker, n.data = encodeInt(int64(t))
+	case int8:
+		n.marker, n.data = encodeInt(int64(t))
+	case int16:
+		n.marker, n.data = encodeInt(int64(t))
+	case int32:
+		n.marker, n.data = encodeInt(int64(t))
+	case int64:
+		n.marker, n.data = encodeInt(t)
+	case uint:
+		if uint64(t) > math.MaxInt64 {
+			return 0, fmt.Errorf("integer %d out of range", t)
+		}
+		n.marker, n.data = encodeInt(int64(t))
+	case uint8:
+		n.marker, n.data = encodeInt(int64(t))
+	case uint16:
+		n.marker, n.data = encodeInt(int64(t))
+	case uint32:
+		n.marker, n.data = encodeInt(int64(t))
+	case uint64:
+		if t > math.MaxInt64 {
+			return 0, fmt.Errorf("integer %d out of range", t)
+		}
+		n.marker, n.data = encodeInt(int64(t))
+	case float64:
+		n.marker = tagReal | 3
+		n.data = binary.BigEndian.AppendUint64(nil, math.Float64bits(t))
+	case float32:
+		n.marker = tagReal | 3
+		n.data = binary.BigEndian.AppendUint64(nil, math.Float64bits(float64(t)))
+	case []byte:
+		n.marker, n.data = encodeCount(tagData, len(t))
+		n.data = append(n.data, t...)
+	case string:
+		if isASCII(t) {
+			n.marker, n.data = encodeCount(tagASCII, len(t))
+			n.data = append(n.data, t...)
+		} else {
+			u := utf16.Encode([]rune(t))
+			n.marker, n.data = encodeCount(tagUTF16, len(u))
+			for _, c := range u {
+				n.data = binary.BigEndian.AppendUint16(n.data, c)
+			}
+		}
+	case []any:
+		n.marker, n.data = encodeCount(tagArray, len(t))
+		for _, elt := range t {
+			r, err := e.flatten(elt)
+			if err != nil {
+				return 0, err
+			}
+			n.refs = append(n.refs, r)
+		}
+	case map[string]any:
+		n.marker, n.data = encodeCount(tagDict, len(t))
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			r, _ := e.flatten(k) // strings cannot fail
+			n.refs = append(n.refs, r)
+		}
+		for _, k := range keys {
+			r, err := e.flatten(t[k])
+			if err != nil {
+				return 0, fmt.Errorf("key %q: %w", k, err)
+			}
+			n.refs = append(n.refs, r)
+		}
+	default:
+		return 0, fmt.Errorf("unsupported type %T", v)
+	}
+	return idx, nil
+}
+
+// encodeInt returns the marker and data for an integer value.  Non-negative
+// values use the smallest of 1, 2, or 4 bytes; others use 8.
+func encodeInt(v int64) (byte, []byte) {
+	u := uint64(v)
+	switch {
+	case v >= 0 && v <= math.MaxUint8:
+		return tagInt | 0, []byte{byte(u)}
+	case v >= 0 && v <= math.MaxUint16:
+		return tagInt | 1, binary.BigEndian.AppendUint16(nil, uint16(u))
+	case v >= 0 && v <= math.MaxUint32:
+		return tagInt | 2, binary.BigEndian.AppendUint32(nil, uint32(u))
+	default:
+		return tagInt | 3, binary.BigEndian.AppendUint64(nil, u)
+	}
+}
+
+// encodeCount returns the marker and length prefix for a variable-sized
+// object with the given tag and length n.
+func encodeCount(tag byte, n int) (byte, []byte) {
+	if n < 0x0f {
+		return tag | byte(n), nil
+	}
+	m, data := encodeInt(int64(n))
+	return tag | 0x0f, append([]byte{m}, data...)
+}
+
+// sizeFor returns the number of bytes needed to represent values up to max.
+func sizeFor(max uint64) int {
+	switch {
+	case max <= math.MaxUint8:
+		return 1
+	case max <= math.MaxUint16:
+		return 2
+	case max <= math.MaxUint32:
+		return 4
+	default:
+		return 8
+	}
+}
+
+// readUint decodes a big-endian unsigned integer of up to 8 bytes.
+func readUint(b []byte) (v uint64) {
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return
+}
+
+// writeUint writes the low-order size bytes of v in big-endian order to buf.
+func writeUint(buf *bytes.Buffer, v uint64, size int) {
+	for i := size - 1; i >= 0; i-- {
+		buf.WriteByte(byte(v >> (8 * i)))
+	}
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
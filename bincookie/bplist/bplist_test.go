@@ -0,0 +1,157 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bplist_test
+
+import (
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/creachadair/cookies/bincookie"
+	"github.com/creachadair/cookies/bincookie/bplist"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDefaultPolicy(t *testing.T) {
+	want := map[string]any{"NSHTTPCookieAcceptPolicy": int64(2)}
+
+	got, err := bplist.Decode([]byte(bincookie.DefaultPolicy))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Decode: (-want, +got)\n%s", diff)
+	}
+
+	// Encoding should reproduce the standard encoding exactly.
+	enc, err := bplist.Encode(want)
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	if diff := cmp.Diff([]byte(bincookie.DefaultPolicy), enc); diff != "" {
+		t.Errorf("Encode: (-want, +got)\n%s", diff)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []any{
+		nil,
+		true,
+		false,
+		int64(0),
+		int64(255),
+		int64(65536),
+		int64(-1),
+		int64(1 << 40),
+		3.25,
+		"",
+		"short",
+		strings.Repeat("long string ", 30),
+		"naïve ☃ 𝄞",
+		[]byte("some data"),
+		[]any{int64(1), "two", []any{int64(3)}},
+		map[string]any{
+			"alpha": int64(1),
+			"bravo": "two",
+			"charlie": map[string]any{
+				"delta": []any{true, false, nil},
+			},
+		},
+	}
+	for _, v := range tests {
+		enc, err := bplist.Encode(v)
+		if err != nil {
+			t.Errorf("Encode %#v: unexpected error: %v", v, err)
+			continue
+		}
+		dec, err := bplist.Decode(enc)
+		if err != nil {
+			t.Errorf("Decode %#v: unexpected error: %v", v, err)
+			continue
+		}
+		if diff := cmp.Diff(v, dec); diff != "" {
+			t.Errorf("Round trip: (-want, +got)\n%s", diff)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	if _, err := bplist.Encode(struct{}{}); err == nil {
+		t.Error("Encode struct: got nil, want error")
+	}
+	if _, err := bplist.Encode(uint64(math.MaxUint64)); err == nil {
+		t.Error("Encode large uint64: got nil, want error")
+	}
+	if _, err := bplist.Encode([]any{uint(math.MaxInt64 + 1)}); err == nil {
+		t.Error("Encode large uint: got nil, want error")
+	}
+	for _, bad := range []string{
+		"",
+		"bplist00",
+		"notplist" + strings.Repeat("\x00", 40),
+		bincookie.DefaultPolicy[:40],
+	} {
+		if v, err := bplist.Decode([]byte(bad)); err == nil {
+			t.Errorf("Decode %q: got %v, want error", bad, v)
+		}
+	}
+}
+
+func TestSharedRefs(t *testing.T) {
+	// Build a list in which each array holds two references to the next, so
+	// that decoding each reference separately would visit 2^depth objects.
+	const depth = 60
+	data := []byte("bplist00")
+	var offsets []byte
+	for i := range depth {
+		offsets = append(offsets, byte(len(data)))
+		data = append(data, 0xa2, byte(i+1), byte(i+1))
+	}
+	offsets = append(offsets, byte(len(data)))
+	data = append(data, 0x10, 0x07) // integer 7
+	tableOff := len(data)
+	data = append(data, offsets...)
+
+	var trailer [32]byte
+	trailer[6], trailer[7] = 1, 1
+	binary.BigEndian.PutUint64(trailer[8:], depth+1)
+	binary.BigEndian.PutUint64(trailer[24:], uint64(tableOff))
+	data = append(data, trailer[:]...)
+
+	done := make(chan struct{})
+	var v any
+	var err error
+	go func() { defer close(done); v, err = bplist.Decode(data) }()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Decode did not finish")
+	}
+	if err != nil {
+		t.Fatalf("Decode: unexpected error: %v", err)
+	}
+	for i := range depth {
+		arr, ok := v.([]any)
+		if !ok || len(arr) != 2 {
+			t.Fatalf("Level %d: got %#v, want a 2-element array", i, v)
+		}
+		v = arr[1]
+	}
+	if v != int64(7) {
+		t.Errorf("Leaf: got %#v, want 7", v)
+	}
+}
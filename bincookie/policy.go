@@ -0,0 +1,96 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bincookie
+
+import (
+	"fmt"
+
+	"github.com/creachadair/cookies/bincookie/bplist"
+)
+
+// policyKey is the property list key for the cookie accept policy.
+const policyKey = "NSHTTPCookieAcceptPolicy"
+
+// An AcceptPolicy is a value of the NSHTTPCookieAcceptPolicy setting.
+type AcceptPolicy int
+
+// Values for the AcceptPolicy enumeration.
+const (
+	AcceptAlways                     AcceptPolicy = 0 // accept all cookies
+	AcceptNever                      AcceptPolicy = 1 // reject all cookies
+	AcceptOnlyFromMainDocumentDomain AcceptPolicy = 2 // accept first-party cookies only (default)
+)
+
+var acceptPolicyStrings = [...]string{"Always", "Never", "OnlyFromMainDocumentDomain"}
+
+func (p AcceptPolicy) String() string {
+	if p < 0 || int(p) >= len(acceptPolicyStrings) {
+		return fmt.Sprintf("AcceptPolicy(%d)", int(p))
+	}
+	return acceptPolicyStrings[p]
+}
+
+// AcceptPolicy reports the cookie accept policy recorded in f.Policy.  If
+// f.Policy is empty, or does not specify an accept policy, it reports the
+// default, AcceptOnlyFromMainDocumentDomain.
+func (f *File) AcceptPolicy() (AcceptPolicy, error) {
+	m, err := f.policyMap()
+	if err != nil {
+		return 0, err
+	}
+	v, ok := m[policyKey]
+	if !ok {
+		return AcceptOnlyFromMainDocumentDomain, nil
+	}
+	n, ok := v.(int64)
+	if !ok {
+		return 0, fmt.Errorf("invalid %s value %T", policyKey, v)
+	}
+	return AcceptPolicy(n), nil
+}
+
+// SetAcceptPolicy updates f.Policy to record the specified cookie accept
+// policy. Any other settings in the existing policy are preserved.
+func (f *File) SetAcceptPolicy(p AcceptPolicy) error {
+	m, err := f.policyMap()
+	if err != nil {
+		return err
+	}
+	m[policyKey] = int64(p)
+	enc, err := bplist.Encode(m)
+	if err != nil {
+		return err
+	}
+	f.Policy = enc
+	return nil
+}
+
+// policyMap decodes the policy property list of f. If f has no policy, it
+// decodes DefaultPolicy instead.
+func (f *File) policyMap() (map[string]any, error) {
+	p := f.Policy
+	if len(p) == 0 {
+		p = []byte(DefaultPolicy)
+	}
+	v, err := bplist.Decode(p)
+	if err != nil {
+		return nil, fmt.Errorf("decoding policy: %w", err)
+	}
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("policy is %T, not a dictionary", v)
+	}
+	return m, nil
+}
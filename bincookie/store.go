@@ -15,8 +15,10 @@
 package bincookie
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"iter"
 	"os"
 
 	"github.com/creachadair/atomicfile"
@@ -45,6 +47,11 @@ type Store struct {
 	path  string
 	file  *File
 	dirty bool
+
+	// index maps cookie keys to the pages containing them. It is built when
+	// needed by the keyed methods, and discarded by operations that may move
+	// cookies between pages.
+	index map[cookies.Key]*Page
 }
 
 // WriteTo encodes the file associated with s in binary format to w.
@@ -52,9 +59,41 @@ func (s *Store) WriteTo(w io.Writer) (int64, error) {
 	return s.file.WriteTo(w)
 }
 
+// AcceptPolicy reports the cookie accept policy of the file associated with s.
+func (s *Store) AcceptPolicy() (AcceptPolicy, error) { return s.file.AcceptPolicy() }
+
+// SetAcceptPolicy sets the cookie accept policy of the file associated with s.
+// The change is written to storage by the next call to Commit.
+func (s *Store) SetAcceptPolicy(p AcceptPolicy) error {
+	if err := s.file.SetAcceptPolicy(p); err != nil {
+		return err
+	}
+	s.dirty = true
+	return nil
+}
+
+// Repaginate sorts and repacks the cookies in s into pages according to opts,
+// as [File.Repaginate]. The change is written to storage by the next call to
+// Commit.
+func (s *Store) Repaginate(opts *PageOptions) {
+	s.file.Repaginate(opts)
+	s.index = nil
+	s.dirty = true
+}
+
 // Scan implements part of the [cookies.Store] interface.
-func (s *Store) Scan(f cookies.ScanFunc) error {
+func (s *Store) Scan(f cookies.ScanFunc) error { return s.ScanContext(context.Background(), f) }
+
+// ScanContext implements the [cookies.ContextStore] interface.  The context is
+// checked before each page is scanned. If ctx ends before the scan is
+// complete, changes already made to earlier pages are retained and will be
+// written by the next call to Commit.
+func (s *Store) ScanContext(ctx context.Context, f cookies.ScanFunc) error {
+	s.index = nil
 	for _, page := range s.file.Pages {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		var out []*Cookie
 		for _, c := range page.Cookies {
 			// Make a temporary copy of the cookie so that edits can be discarded
@@ -81,6 +120,20 @@ func (s *Store) Scan(f cookies.ScanFunc) error {
 	return nil
 }
 
+// All implements the [cookies.IterStore] interface.  It never reports an
+// error.
+func (s *Store) All() iter.Seq2[cookies.C, error] {
+	return func(yield func(cookies.C, error) bool) {
+		for _, page := range s.file.Pages {
+			for _, c := range page.Cookies {
+				if !yield(c.Get(), nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
 // Commit implements part of the [cookies.Store] interface.
 func (s *Store) Commit() error {
 	if s.dirty {
@@ -91,3 +144,94 @@ func (s *Store) Commit() error {
 	}
 	return nil
 }
+
+// find returns the page and index of the cookie in s with the given key.
+// If there is no such cookie, find returns nil, -1.
+func (s *Store) find(key cookies.Key) (*Page, int) {
+	if s.index == nil {
+		s.buildIndex()
+	}
+	page, ok := s.index[key]
+	if !ok {
+		return nil, -1
+	}
+	if i := page.indexOf(key); i >= 0 {
+		return page, i
+	}
+
+	// The index is out of date with the pages; rebuild it and try again.
+	s.buildIndex()
+	if page, ok := s.index[key]; ok {
+		return page, page.indexOf(key)
+	}
+	return nil, -1
+}
+
+// buildIndex rebuilds the index of s from the contents of its pages.
+func (s *Store) buildIndex() {
+	s.index = make(map[cookies.Key]*Page)
+	for _, page := range s.file.Pages {
+		for _, c := range page.Cookies {
+			k := cookies.Key{Domain: c.URL, Name: c.Name, Path: c.Path}
+			if _, ok := s.index[k]; !ok {
+				s.index[k] = page
+			}
+		}
+	}
+}
+
+// indexOf returns the index of the cookie in p with the given key, or -1.
+func (p *Page) indexOf(key cookies.Key) int {
+	for i, c := range p.Cookies {
+		if c.URL == key.Domain && c.Name == key.Name && c.Path == key.Path {
+			return i
+		}
+	}
+	return -1
+}
+
+// Get implements part of the [cookies.KeyedStore] interface.
+func (s *Store) Get(key cookies.Key) (cookies.C, error) {
+	page, i := s.find(key)
+	if page == nil {
+		return cookies.C{}, cookies.ErrNotFound
+	}
+	return page.Cookies[i].Get(), nil
+}
+
+// Put implements part of the [cookies.KeyedStore] interface.  A new cookie is
+// added to the last page of the file. The change is written to storage by the
+// next call to Commit.
+func (s *Store) Put(c cookies.C) error {
+	if page, i := s.find(c.Key()); page != nil {
+		tmp := *page.Cookies[i]
+		if err := tmp.Set(c); err != nil {
+			return err
+		}
+		page.Cookies[i] = &tmp
+	} else {
+		var nc Cookie
+		if err := nc.Set(c); err != nil {
+			return err
+		}
+		if len(s.file.Pages) == 0 {
+			s.file.Pages = append(s.file.Pages, new(Page))
+		}
+		last := s.file.Pages[len(s.file.Pages)-1]
+		last.Cookies = append(last.Cookies, &nc)
+		s.index[c.Key()] = last
+	}
+	s.dirty = true
+	return nil
+}
+
+// Delete implements part of the [cookies.KeyedStore] interface.  The change is
+// written to storage by the next call to Commit.
+func (s *Store) Delete(key cookies.Key) error {
+	if page, i := s.find(key); page != nil {
+		page.Cookies = append(page.Cookies[:i:i], page.Cookies[i+1:]...)
+		s.index = nil // another page may hold a duplicate
+		s.dirty = true
+	}
+	return nil
+}
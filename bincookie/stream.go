@@ -0,0 +1,240 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bincookie
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// A Reader reads the contents of a bincookie file incrementally, one page at
+// a time, without loading the whole file into memory.
+type Reader struct {
+	r     io.ReaderAt
+	sizes []int64   // page sizes, from the file header
+	next  int       // index of the next unread page
+	pos   int64     // offset of the next unread page
+	queue []*Cookie // unread cookies from the current page
+}
+
+// NewReader constructs a Reader that consumes a bincookie file from r.  It
+// reads and checks the file header, but does not read any pages.
+func NewReader(r io.ReaderAt) (*Reader, error) {
+	var hdr [8]byte
+	if err := readFullAt(r, hdr[:], 0); err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	} else if !bytes.HasPrefix(hdr[:], []byte(fileMagic)) {
+		return nil, errors.New("invalid file magic")
+	}
+	numPages := int64(binary.BigEndian.Uint32(hdr[4:]))
+
+	// Read the page sizes through a buffer rather than allocating space for
+	// all of them up front, since the page count has not been validated.
+	br := bufio.NewReader(io.NewSectionReader(r, 8, 4*numPages))
+	rd := &Reader{r: r, pos: 8 + 4*numPages}
+	for i := range numPages {
+		var buf [4]byte
+		if _, err := io.ReadFull(br, buf[:]); err != nil {
+			return nil, fmt.Errorf("reading size of page %d: %w", i+1, err)
+		}
+		rd.sizes = append(rd.sizes, int64(binary.BigEndian.Uint32(buf[:])))
+	}
+	return rd, nil
+}
+
+// NumPages reports the total number of pages in the file.
+func (r *Reader) NumPages() int { return len(r.sizes) }
+
+// NextPage reads and returns the next unread page of the file, discarding
+// any unread cookies from the current page. It returns io.EOF after the last
+// page has been read.
+func (r *Reader) NextPage() (*Page, error) {
+	r.queue = nil
+	if r.next >= len(r.sizes) {
+		return nil, io.EOF
+	}
+	size := r.sizes[r.next]
+	data, err := readSection(r.r, r.pos, size)
+	if err != nil {
+		return nil, fmt.Errorf("page %d truncated: %w", r.next+1, err)
+	}
+	page, err := parsePage(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing page: %w", err)
+	}
+	r.next++
+	r.pos += size
+	return page, nil
+}
+
+// Next returns the next cookie in the file, reading pages as needed.  It
+// returns io.EOF after the last cookie has been read.
+func (r *Reader) Next() (*Cookie, error) {
+	for len(r.queue) == 0 {
+		page, err := r.NextPage()
+		if err != nil {
+			return nil, err
+		}
+		r.queue = page.Cookies
+	}
+	c := r.queue[0]
+	r.queue = r.queue[1:]
+	return c, nil
+}
+
+// Footer reads and returns the checksum and the cookie accept policy stored
+// after the last page of the file. It may be called at any time, and does not
+// affect the reading of pages and cookies.
+func (r *Reader) Footer() (checksum uint32, policy []byte, _ error) {
+	end := 8 + 4*int64(len(r.sizes))
+	for _, size := range r.sizes {
+		end += size
+	}
+
+	// The footer consists of a fixed 8-byte prefix, followed by an optional
+	// length-prefixed policy message.
+	buf := make([]byte, 12)
+	nr, err := r.r.ReadAt(buf, end)
+	if nr < 8 {
+		if err == nil || err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, nil, fmt.Errorf("reading footer: %w", err)
+	}
+	buf = buf[:nr]
+	if nr == 12 {
+		plen := binary.BigEndian.Uint32(buf[8:])
+		policy, err := readSection(r.r, end+12, int64(plen))
+		if err != nil {
+			return 0, nil, fmt.Errorf("reading policy: %w", err)
+		}
+		buf = append(buf, policy...)
+	}
+	return parseFooter(buf)
+}
+
+// readFullAt reads exactly len(buf) bytes from r at offset.
+func readFullAt(r io.ReaderAt, buf []byte, offset int64) error {
+	nr, err := r.ReadAt(buf, offset)
+	if nr == len(buf) {
+		return nil // N.B. ReadAt may report io.EOF along with a full read
+	} else if err == nil || err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// readSection reads exactly n bytes from r at offset. The result grows as the
+// data are read, so a corrupt length in the file cannot force an allocation
+// much larger than the file itself.
+func readSection(r io.ReaderAt, offset, n int64) ([]byte, error) {
+	data, err := io.ReadAll(io.NewSectionReader(r, offset, n))
+	if err != nil {
+		return nil, err
+	} else if int64(len(data)) < n {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return data, nil
+}
+
+// A Writer writes a bincookie file incrementally, one page at a time, so that
+// only the page being written needs to be held in memory.
+//
+// Because the file header records the size of every page, the total number of
+// pages must be known in advance, and the output must support random access.
+type Writer struct {
+	w        io.WriterAt
+	numPages int
+	next     int    // index of the next page to write
+	pos      int64  // offset of the next page
+	checksum uint32 // running sum of page checksums
+	buf      bytes.Buffer
+
+	// The cookie acceptance policy, as a binary-format property list.  If
+	// empty when the writer is closed, DefaultPolicy is written.
+	Policy []byte
+}
+
+// NewWriter constructs a Writer that writes a file of exactly numPages pages
+// to w, beginning at offset 0.
+func NewWriter(w io.WriterAt, numPages int) *Writer {
+	return &Writer{w: w, numPages: numPages, pos: 8 + 4*int64(numPages)}
+}
+
+// WritePage encodes and writes p as the next page of the file.  It reports an
+// error if p is empty, or if all the pages have already been written.
+func (w *Writer) WritePage(p *Page) error {
+	if w.next >= w.numPages {
+		return fmt.Errorf("too many pages (want %d)", w.numPages)
+	} else if len(p.Cookies) == 0 {
+		return errors.New("page has no cookies")
+	}
+	w.buf.Reset()
+	if w.next == 0 {
+		// Write the header before the first page, so that a file abandoned
+		// partway through is recognizably incomplete.
+		w.buf.WriteString(fileMagic)
+		writeBig32(&w.buf, uint32(w.numPages))
+		if _, err := w.w.WriteAt(w.buf.Bytes(), 0); err != nil {
+			return err
+		}
+		w.buf.Reset()
+	}
+	if _, err := p.WriteTo(&w.buf); err != nil {
+		return err
+	}
+	data := w.buf.Bytes()
+	if _, err := w.w.WriteAt(data, w.pos); err != nil {
+		return err
+	}
+
+	// Record the size of the page in the header.
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(data)))
+	if _, err := w.w.WriteAt(size[:], 8+4*int64(w.next)); err != nil {
+		return err
+	}
+	w.checksum += pageChecksum(data)
+	w.next++
+	w.pos += int64(len(data))
+	return nil
+}
+
+// Checksum reports the checksum of the pages written so far.
+func (w *Writer) Checksum() uint32 { return w.checksum }
+
+// Close writes the footer of the file, and reports an error if fewer pages
+// were written than the count given to NewWriter. It does not close the
+// underlying writer.
+func (w *Writer) Close() error {
+	if w.next != w.numPages {
+		return fmt.Errorf("wrote %d pages, want %d", w.next, w.numPages)
+	}
+	w.buf.Reset()
+	off := w.pos
+	if w.numPages == 0 {
+		// No pages were written, so the header has not been written either.
+		w.buf.WriteString(fileMagic)
+		writeBig32(&w.buf, 0)
+		off = 0
+	}
+	writeFooter(&w.buf, w.checksum, w.Policy)
+	_, err := w.w.WriteAt(w.buf.Bytes(), off)
+	return err
+}
@@ -0,0 +1,361 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cdp converts cookies to and from the JSON types of the Chrome
+// DevTools Protocol (CDP), and supports reading and modifying files of
+// cookies in that format.
+//
+// The [Cookie] type corresponds to Network.Cookie, as reported by methods such
+// as Network.getAllCookies. The [CookieParam] type corresponds to
+// Network.CookieParam, as accepted by Network.setCookies. To preload a
+// browser with the cookies from a store:
+//
+//	var params []*cdp.CookieParam
+//	for c, err := range cookies.All(src) {
+//		if err != nil {
+//			log.Fatalf("Reading cookies: %v", err)
+//		}
+//		params = append(params, cdp.Param(c))
+//	}
+//	// ... send params to Network.setCookies
+//
+// See https://chromedevtools.github.io/devtools-protocol/tot/Network/.
+package cdp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/creachadair/cookies"
+)
+
+// A Cookie is a cookie as reported by the DevTools protocol (Network.Cookie).
+//
+// A Cookie also satisfies [cookies.AttrEditor], with the following attributes:
+//
+//	priority     -- string, "Low", "Medium", or "High"
+//	sourceScheme -- string, "Unset", "NonSecure", or "Secure"
+//	sourcePort   -- int, the port of the origin that set the cookie, or -1
+//	partitionKey -- string, the top-level site of a partitioned cookie, or ""
+type Cookie struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Domain string `json:"domain"` // with a leading period for domain cookies
+	Path   string `json:"path"`
+
+	// The expiration time in seconds since the Unix epoch, or -1 for a
+	// session cookie.
+	Expires float64 `json:"expires"`
+
+	Size     int    `json:"size"` // the combined length of the name and value
+	HTTPOnly bool   `json:"httpOnly"`
+	Secure   bool   `json:"secure"`
+	Session  bool   `json:"session"`
+	SameSite string `json:"sameSite,omitempty"` // "Strict", "Lax", or "None"
+
+	Priority           string        `json:"priority,omitempty"`
+	SourceScheme       string        `json:"sourceScheme,omitempty"`
+	SourcePort         int           `json:"sourcePort"`
+	PartitionKey       *PartitionKey `json:"partitionKey,omitempty"`
+	PartitionKeyOpaque bool          `json:"partitionKeyOpaque,omitempty"`
+}
+
+// Get returns a format-independent representation of c.
+// It satisfies part of [cookies.Editor].
+func (c *Cookie) Get() cookies.C {
+	domain, hostOnly := cookies.ParseHostKey(c.Domain)
+	out := cookies.C{
+		Name:   c.Name,
+		Value:  c.Value,
+		Domain: domain,
+		Path:   c.Path,
+		Flags: cookies.Flags{
+			Secure:      c.Secure,
+			HTTPOnly:    c.HTTPOnly,
+			HostOnly:    hostOnly,
+			Partitioned: c.PartitionKey != nil,
+		},
+		SameSite: decodeSitePolicy(c.SameSite),
+	}
+	if !c.Session {
+		out.Expires = decodeExpires(c.Expires)
+	}
+	return out
+}
+
+// Set updates c to match the contents of o. The format does not record the
+// creation time of a cookie, so o.Created is discarded. The CDP-specific
+// fields of c, including its partition key, are not changed.
+// It satisfies part of [cookies.Editor].
+func (c *Cookie) Set(o cookies.C) error {
+	if err := cookies.Validate(o); err != nil {
+		return err
+	}
+	c.Name = o.Name
+	c.Value = o.Value
+	c.Domain = o.HostKey()
+	c.Path = o.Path
+	c.Expires = encodeExpires(o.Expires)
+	c.Size = len(o.Name) + len(o.Value)
+	c.HTTPOnly = o.Flags.HTTPOnly
+	c.Secure = o.Flags.Secure
+	c.Session = o.Expires.IsZero()
+	c.SameSite = encodeSitePolicy(o.SameSite)
+	return nil
+}
+
+// NewCookie returns a new Cookie with the contents of c, and CDP-specific
+// fields set to the defaults Chrome uses for cookies set by a page.
+func NewCookie(c cookies.C) (*Cookie, error) {
+	out := &Cookie{Priority: "Medium", SourceScheme: "Unset", SourcePort: -1}
+	if err := out.Set(c); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Attrs returns the CDP-specific attributes of c.
+// It satisfies part of [cookies.AttrEditor].
+func (c *Cookie) Attrs() map[string]any {
+	var pk string
+	if c.PartitionKey != nil {
+		pk = c.PartitionKey.TopLevelSite
+	}
+	return map[string]any{
+		"priority":     c.Priority,
+		"sourceScheme": c.SourceScheme,
+		"sourcePort":   c.SourcePort,
+		"partitionKey": pk,
+	}
+}
+
+// SetAttr sets the named CDP-specific attribute of c.
+// It satisfies part of [cookies.AttrEditor].
+func (c *Cookie) SetAttr(name string, value any) error {
+	switch name {
+	case "priority", "sourceScheme", "partitionKey":
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("attribute %q: got %T, want string", name, value)
+		}
+		switch name {
+		case "priority":
+			c.Priority = v
+		case "sourceScheme":
+			c.SourceScheme = v
+		case "partitionKey":
+			if v == "" {
+				c.PartitionKey = nil
+			} else {
+				c.PartitionKey = &PartitionKey{TopLevelSite: v}
+			}
+		}
+	case "sourcePort":
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("attribute %q: got %T, want int", name, value)
+		}
+		c.SourcePort = v
+	default:
+		return fmt.Errorf("unknown attribute %q", name)
+	}
+	return nil
+}
+
+// Param returns a CookieParam that sets a cookie with the contents of c,
+// including its CDP-specific fields.
+func (c *Cookie) Param() *CookieParam {
+	p := Param(c.Get())
+	p.Priority = c.Priority
+	p.SourceScheme = c.SourceScheme
+	if c.SourcePort > 0 {
+		p.SourcePort = c.SourcePort
+	}
+	p.PartitionKey = c.PartitionKey
+	return p
+}
+
+// A PartitionKey identifies the partition of a partitioned cookie.  Older
+// versions of the protocol represent it as a string holding the top-level
+// site, which is also accepted when decoding.
+type PartitionKey struct {
+	TopLevelSite         string `json:"topLevelSite"`
+	HasCrossSiteAncestor bool   `json:"hasCrossSiteAncestor"`
+}
+
+// UnmarshalJSON implements the [json.Unmarshaler] interface.
+func (k *PartitionKey) UnmarshalJSON(data []byte) error {
+	var site string
+	if err := json.Unmarshal(data, &site); err == nil {
+		*k = PartitionKey{TopLevelSite: site}
+		return nil
+	}
+	type plain PartitionKey
+	return json.Unmarshal(data, (*plain)(k))
+}
+
+// A CookieParam describes a cookie to be set through the DevTools protocol
+// (Network.CookieParam).
+type CookieParam struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+
+	// If set, the URL with which to associate the cookie, from which the
+	// default domain and path are derived. A host-only cookie can only be set
+	// by URL, with Domain empty.
+	URL string `json:"url,omitempty"`
+
+	Domain   string  `json:"domain,omitempty"`
+	Path     string  `json:"path,omitempty"`
+	Secure   bool    `json:"secure,omitempty"`
+	HTTPOnly bool    `json:"httpOnly,omitempty"`
+	SameSite string  `json:"sameSite,omitempty"`
+	Expires  float64 `json:"expires,omitempty"` // seconds since the epoch; if omitted, a session cookie
+
+	Priority     string        `json:"priority,omitempty"`
+	SourceScheme string        `json:"sourceScheme,omitempty"`
+	SourcePort   int           `json:"sourcePort,omitempty"`
+	PartitionKey *PartitionKey `json:"partitionKey,omitempty"`
+}
+
+// Param returns a CookieParam that sets a cookie with the contents of c.
+// A host-only cookie is set by URL, since a cookie set with a domain is
+// always a domain cookie.
+//
+// Since c does not record the top-level site of a partitioned cookie, the
+// result has no partition key, and the cookie is set unpartitioned. To keep
+// the partition of a cookie reported by the protocol, use [Cookie.Param], or
+// set the PartitionKey of the result.
+func Param(c cookies.C) *CookieParam {
+	p := &CookieParam{
+		Name:     c.Name,
+		Value:    c.Value,
+		Path:     c.Path,
+		Secure:   c.Flags.Secure,
+		HTTPOnly: c.Flags.HTTPOnly,
+		SameSite: encodeSitePolicy(c.SameSite),
+	}
+	if !c.Expires.IsZero() {
+		p.Expires = encodeExpires(c.Expires)
+	}
+	if c.Flags.HostOnly {
+		host := c.Domain
+		if strings.Contains(host, ":") {
+			host = "[" + host + "]" // an IPv6 address
+		}
+		u := &url.URL{Scheme: "http", Host: host, Path: c.Path}
+		if c.Flags.Secure {
+			u.Scheme = "https"
+		}
+		if u.Path == "" {
+			u.Path = "/"
+		}
+		p.URL = u.String()
+	} else {
+		p.Domain = c.HostKey()
+	}
+	return p
+}
+
+// C returns a format-independent representation of the cookie described by
+// p. If p has a URL and no domain, the result is a host-only cookie for the
+// host of the URL. If p has no path, the path of the URL is used, or "/".
+func (p *CookieParam) C() (cookies.C, error) {
+	out := cookies.C{
+		Name:  p.Name,
+		Value: p.Value,
+		Path:  p.Path,
+		Flags: cookies.Flags{
+			Secure:      p.Secure,
+			HTTPOnly:    p.HTTPOnly,
+			Partitioned: p.PartitionKey != nil,
+		},
+		SameSite: decodeSitePolicy(p.SameSite),
+	}
+	if p.Expires > 0 {
+		out.Expires = decodeExpires(p.Expires)
+	}
+	var u *url.URL
+	if p.URL != "" {
+		var err error
+		u, err = url.Parse(p.URL)
+		if err != nil {
+			return cookies.C{}, fmt.Errorf("invalid URL: %w", err)
+		}
+	}
+	switch {
+	case p.Domain != "":
+		// A cookie set with an explicit domain is always a domain cookie.
+		out.Domain = strings.TrimPrefix(p.Domain, ".")
+	case u != nil:
+		out.Domain = u.Hostname()
+		out.Flags.HostOnly = true
+	default:
+		return cookies.C{}, errors.New("cookie has neither domain nor URL")
+	}
+	if out.Path == "" {
+		if u != nil && u.Path != "" {
+			out.Path = u.Path
+		} else {
+			out.Path = "/"
+		}
+	}
+	return out, nil
+}
+
+func decodeExpires(v float64) time.Time {
+	if v < 0 {
+		return time.Time{}
+	}
+	sec, frac := math.Modf(v)
+	return time.Unix(int64(sec), int64(frac*1e9)).UTC()
+}
+
+func encodeExpires(t time.Time) float64 {
+	if t.IsZero() {
+		return -1
+	}
+	return float64(t.UnixNano()) / 1e9
+}
+
+func decodeSitePolicy(s string) cookies.SameSite {
+	switch s {
+	case "Strict":
+		return cookies.Strict
+	case "Lax":
+		return cookies.Lax
+	case "None":
+		return cookies.None
+	default:
+		return cookies.Unknown
+	}
+}
+
+func encodeSitePolicy(p cookies.SameSite) string {
+	switch p {
+	case cookies.Strict:
+		return "Strict"
+	case cookies.Lax:
+		return "Lax"
+	case cookies.None:
+		return "None"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cdp_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/creachadair/cookies"
+	"github.com/creachadair/cookies/cdp"
+	"github.com/google/go-cmp/cmp"
+)
+
+// testCookies is in the form returned by Network.getAllCookies.
+const testCookies = `{"cookies": [
+  {
+    "name": "sid", "value": "abc123", "domain": ".example.com", "path": "/",
+    "expires": 1798761600, "size": 9, "httpOnly": true, "secure": true,
+    "session": false, "sameSite": "Lax", "priority": "High",
+    "sourceScheme": "Secure", "sourcePort": 443,
+    "partitionKey": {"topLevelSite": "https://example.com", "hasCrossSiteAncestor": false}
+  },
+  {
+    "name": "tmp", "value": "x", "domain": "www.example.com", "path": "/app",
+    "expires": -1, "size": 4, "httpOnly": false, "secure": false,
+    "session": true, "priority": "Medium", "sourceScheme": "NonSecure",
+    "sourcePort": 80, "partitionKey": "https://other.com"
+  }
+]}`
+
+func TestCookie(t *testing.T) {
+	var obj struct {
+		Cookies []*cdp.Cookie `json:"cookies"`
+	}
+	if err := json.Unmarshal([]byte(testCookies), &obj); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	want := []cookies.C{{
+		Name: "sid", Value: "abc123", Domain: "example.com", Path: "/",
+		Expires:  time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+		Flags:    cookies.Flags{Secure: true, HTTPOnly: true, Partitioned: true},
+		SameSite: cookies.Lax,
+	}, {
+		Name: "tmp", Value: "x", Domain: "www.example.com", Path: "/app",
+		Flags: cookies.Flags{HostOnly: true, Partitioned: true},
+	}}
+	var got []cookies.C
+	for _, c := range obj.Cookies {
+		got = append(got, c.Get())
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Cookies (-want, +got):\n%s", diff)
+	}
+
+	wantAttrs := map[string]any{
+		"priority": "Medium", "sourceScheme": "NonSecure", "sourcePort": 80,
+		"partitionKey": "https://other.com",
+	}
+	if diff := cmp.Diff(wantAttrs, obj.Cookies[1].Attrs()); diff != "" {
+		t.Errorf("Attrs (-want, +got):\n%s", diff)
+	}
+
+	// Converting to a parameter preserves the CDP-specific fields.
+	p := obj.Cookies[0].Param()
+	if p.Domain != ".example.com" || p.URL != "" || p.Priority != "High" || p.SourcePort != 443 {
+		t.Errorf("Param: got %+v, want domain .example.com, priority High, port 443", p)
+	}
+	if c, err := p.C(); err != nil {
+		t.Errorf("Param C: unexpected error: %v", err)
+	} else if diff := cmp.Diff(want[0], c); diff != "" {
+		t.Errorf("Param C (-want, +got):\n%s", diff)
+	}
+}
+
+func TestParam(t *testing.T) {
+	tests := []struct {
+		c    cookies.C
+		want cdp.CookieParam
+	}{
+		{cookies.C{Name: "a", Value: "1", Domain: "example.com", Path: "/"},
+			cdp.CookieParam{Name: "a", Value: "1", Domain: ".example.com", Path: "/"}},
+		{cookies.C{Name: "b", Value: "2", Domain: "www.example.com", Path: "/x",
+			Flags: cookies.Flags{HostOnly: true, Secure: true}, SameSite: cookies.Strict,
+			Expires: time.Unix(1800000000, 0)},
+			cdp.CookieParam{Name: "b", Value: "2", URL: "https://www.example.com/x", Path: "/x",
+				Secure: true, SameSite: "Strict", Expires: 1800000000}},
+		{cookies.C{Name: "c", Value: "3", Domain: "::1", Path: "/",
+			Flags: cookies.Flags{HostOnly: true}},
+			cdp.CookieParam{Name: "c", Value: "3", URL: "http://[::1]/", Path: "/"}},
+	}
+	for _, tc := range tests {
+		p := cdp.Param(tc.c)
+		if diff := cmp.Diff(&tc.want, p); diff != "" {
+			t.Errorf("Param(%+v) (-want, +got):\n%s", tc.c, diff)
+		}
+		c, err := p.C()
+		if err != nil {
+			t.Fatalf("C: unexpected error: %v", err)
+		}
+		if diff := cmp.Diff(tc.c, c); diff != "" {
+			t.Errorf("C round trip (-want, +got):\n%s", diff)
+		}
+	}
+}
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cookies.json")
+	if err := os.WriteFile(path, []byte(testCookies), 0600); err != nil {
+		t.Fatalf("Write file: %v", err)
+	}
+	s, err := cdp.Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	var _ cookies.KeyedStore = s
+	var _ cookies.IterStore = s
+
+	// Update the value of one cookie, and add another.
+	if err := s.Scan(func(e cookies.Editor) (cookies.Action, error) {
+		c := e.Get()
+		if c.Name != "sid" {
+			return cookies.Keep, nil
+		}
+		c.Value = "updated"
+		return cookies.Update, e.Set(c)
+	}); err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if err := s.Put(cookies.C{Name: "new", Value: "v", Domain: "example.org", Path: "/"}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := s.Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	s, err = cdp.Open(path)
+	if err != nil {
+		t.Fatalf("Reopen: %v", err)
+	}
+	cs := s.Cookies()
+	if len(cs) != 3 {
+		t.Fatalf("Got %d cookies, want 3", len(cs))
+	}
+	if cs[0].Value != "updated" || cs[0].Size != 10 || cs[0].Priority != "High" {
+		t.Errorf("Updated cookie: got %+v", cs[0])
+	}
+	if cs[2].Priority != "Medium" || cs[2].SourcePort != -1 || !cs[2].Session {
+		t.Errorf("New cookie: got %+v", cs[2])
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Read file: %v", err)
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil || obj["cookies"] == nil {
+		t.Errorf("Output is not a cookies object: %v\n%s", err, data)
+	}
+}
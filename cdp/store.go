@@ -0,0 +1,135 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cdp
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"iter"
+	"os"
+
+	"github.com/creachadair/cookies"
+	"github.com/creachadair/cookies/internal/filestore"
+)
+
+// Open opens a file of cookies in CDP format, and returns a Store containing
+// its data. The file may contain either a JSON array of Network.Cookie
+// objects, or an object with a "cookies" field holding such an array, as
+// returned by Network.getAllCookies. When the store is committed, the file
+// is written in the same form.
+func Open(path string) (*Store, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := newStore(path)
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("{")) {
+		var obj struct {
+			Cookies []*Cookie `json:"cookies"`
+		}
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return nil, err
+		}
+		s.list.Cookies, s.wrapped = obj.Cookies, true
+	} else if err := json.Unmarshal(data, &s.list.Cookies); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// New returns an empty Store that will be written to path by Commit, as a
+// JSON array of cookies.
+func New(path string) *Store {
+	s := newStore(path)
+	s.list.Dirty = true
+	return s
+}
+
+// newStore returns an empty Store for path. Cookies added by Put that are not
+// already in the store get the defaults of NewCookie.
+func newStore(path string) *Store {
+	s := &Store{path: path}
+	s.list.New = NewCookie
+	return s
+}
+
+// A Store represents a file of cookies in CDP format, and satisfies the
+// [cookies.KeyedStore] and [cookies.IterStore] interfaces.
+type Store struct {
+	path    string
+	list    filestore.List[Cookie, *Cookie]
+	wrapped bool // the file is an object with a "cookies" field
+}
+
+// Cookies returns the cookies in s, in the order they appear in the file.
+func (s *Store) Cookies() []*Cookie { return s.list.Cookies }
+
+// Params returns CookieParam values to set each of the cookies in s, in the
+// order they appear in the file.
+func (s *Store) Params() []*CookieParam {
+	out := make([]*CookieParam, len(s.list.Cookies))
+	for i, c := range s.list.Cookies {
+		out[i] = c.Param()
+	}
+	return out
+}
+
+// WriteTo encodes the cookies in s as JSON to w.
+func (s *Store) WriteTo(w io.Writer) (int64, error) {
+	cs := s.list.Cookies
+	if cs == nil {
+		cs = []*Cookie{}
+	}
+	var v any = cs
+	if s.wrapped {
+		v = map[string]any{"cookies": cs}
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return 0, err
+	}
+	return buf.WriteTo(w)
+}
+
+// Scan implements part of the [cookies.Store] interface.
+func (s *Store) Scan(f cookies.ScanFunc) error { return s.list.Scan(f) }
+
+// All implements the [cookies.IterStore] interface, reporting the cookies in
+// file order.
+func (s *Store) All() iter.Seq2[cookies.C, error] { return s.list.All() }
+
+// Get implements part of the [cookies.KeyedStore] interface.
+func (s *Store) Get(key cookies.Key) (cookies.C, error) { return s.list.Get(key) }
+
+// Put implements part of the [cookies.KeyedStore] interface.  An existing
+// cookie keeps its priority, source scheme and port, and partition key; a new
+// cookie gets the defaults of [NewCookie].
+func (s *Store) Put(c cookies.C) error { return s.list.Put(c) }
+
+// Delete implements part of the [cookies.KeyedStore] interface.
+func (s *Store) Delete(key cookies.Key) error { return s.list.Delete(key) }
+
+// Commit implements part of the [cookies.Store] interface.  The file keeps the
+// form, array or wrapped object, in which it was read.
+func (s *Store) Commit() error {
+	return s.list.Commit(s.path, func(w io.Writer) error {
+		_, err := s.WriteTo(w)
+		return err
+	})
+}
@@ -16,14 +16,18 @@
 package chromedb
 
 import (
+	"context"
 	"crypto/sha256"
 	"database/sql"
 	"errors"
 	"fmt"
+	"iter"
 	"runtime"
+	"strings"
 	"time"
 
 	"github.com/creachadair/cookies"
+	"github.com/creachadair/cookies/internal/dbfile"
 )
 
 const (
@@ -31,10 +35,10 @@ const (
 SELECT 
   rowid, name, value, encrypted_value, host_key, path,
   expires_utc, creation_utc,
-  is_secure, is_httponly, samesite
+  is_secure, is_httponly, samesite, priority
 FROM cookies`
 
-	writeCookieStmt = `
+	updateCookieStmt = `
 UPDATE cookies SET
   name = $name,
   %[1]s = $value,
@@ -44,11 +48,19 @@ UPDATE cookies SET
   creation_utc = $created,
   is_secure = $secure,
   is_httponly = $httponly,
-  samesite = $samesite
+  samesite = $samesite`
+
+	writeCookieStmt = updateCookieStmt + `,
+  priority = $priority
 WHERE rowid = $rowid`
 
+	putCookieStmt = updateCookieStmt + `
+WHERE host_key = $host AND name = $name AND path = $path`
+
 	dropCookieStmt = `DELETE FROM cookies WHERE rowid = $rowid`
 
+	deleteKeyStmt = `DELETE FROM cookies WHERE host_key = $host AND name = $name AND path = $path`
+
 	versionStmt = `SELECT value FROM meta WHERE key = 'version'`
 
 	// The Chrome timestamp epoch in seconds, 1601-01-01T00:00:00Z.
@@ -61,15 +73,47 @@ WHERE rowid = $rowid`
 
 // Open opens the Chrome cookie database at the specified path.
 func Open(path string, opts *Options) (*Store, error) {
-	db, err := sql.Open(opts.driver(), path)
+	if !opts.readOnly() && !opts.snapshot() && !opts.ignoreLock() {
+		if err := checkLock(path); err != nil {
+			return nil, err
+		}
+	}
+	var snap *dbfile.Snapshot
+	if opts.snapshot() {
+		var err error
+		snap, err = dbfile.NewSnapshot(path)
+		if err != nil {
+			return nil, fmt.Errorf("snapshot: %w", err)
+		}
+		path = snap.Path
+	}
+	db, err := sql.Open(opts.driver(), dbfile.URI(path, opts.readOnly() && snap == nil))
 	if err != nil {
+		closeSnapshot(snap)
 		return nil, err
 	}
+	s, err := OpenDB(db, opts)
+	if err != nil {
+		db.Close()
+		closeSnapshot(snap)
+		return nil, err
+	}
+	s.ownDB = true
+	s.readOnly = s.readOnly || snap != nil
+	s.snap = snap
+	return s, nil
+}
+
+// OpenDB returns a Store that reads and modifies the Chrome cookie database
+// accessed through db. The caller remains responsible for closing db; the
+// Close method of the resulting store does not close it.
+//
+// The Driver, Snapshot, and IgnoreLock options do not apply to OpenDB.
+func OpenDB(db *sql.DB, opts *Options) (*Store, error) {
 	row := db.QueryRow(versionStmt)
 	var version int
 	if err := row.Scan(&version); err != nil {
 		if !errors.Is(err, sql.ErrNoRows) {
-			db.Close()
 			return nil, err
 		}
 	}
@@ -77,9 +121,16 @@ func Open(path string, opts *Options) (*Store, error) {
 		db:        db,
 		key:       opts.encryptionKey(),
 		dbVersion: version,
+		readOnly:  opts.readOnly(),
 	}, nil
 }
 
+func closeSnapshot(s *dbfile.Snapshot) {
+	if s != nil {
+		s.Close()
+	}
+}
+
 // Options provide optional settings for opening a Chrome cookie database.
 // A nil *Options is ready for use, and provides empty values.
 type Options struct {
@@ -88,8 +139,36 @@ type Options struct {
 	// The number of PBKDF2 iterations to use when converting the passphrase
 	// into an encryption key. If ≤ 0, use a default based on runtime.GOOS.
 	Iterations int
+
+	// If true, open the database read-only and treat it as immutable, so that
+	// no locks are taken. Chrome writes the Cookies file while it runs, so
+	// reading it this way while Chrome is open may give incorrect results or
+	// a corruption error. To read the cookies of a running Chrome, use
+	// Snapshot.
+	ReadOnly bool
+
+	// If true, copy the Cookies file and its journal or write-ahead log into
+	// a temporary directory and open the copy, so that the cookies of a
+	// running Chrome can be read without contending for its locks. The
+	// resulting store is read-only, and must be closed to remove the copy.
+	Snapshot bool
+
+	// If true, open the database for writing even if the browser appears to
+	// be running. By default, Open reports an error wrapping cookies.ErrLocked
+	// in that case, since the browser may overwrite or corrupt the changes.
+	IgnoreLock bool
+
+	// The name of the database/sql driver to use to open the database.  The
+	// driver must be registered by the caller, typically with a blank import.
+	// If empty, "sqlite" is used, as registered by modernc.org/sqlite.
+	Driver string
 }
 
+func (o *Options) readOnly() bool { return o != nil && o.ReadOnly }
+func (o *Options) snapshot() bool { return o != nil && o.Snapshot }
+
+func (o *Options) ignoreLock() bool { return o != nil && o.IgnoreLock }
+
 // encryptionKey returns the encryption key generated from o, or nil.
 func (o *Options) encryptionKey() []byte {
 	if o == nil || o.Passphrase == "" {
@@ -107,7 +186,12 @@ func (o *Options) encryptionKey() []byte {
 	return encryptionKey(o.Passphrase, iter)
 }
 
-func (*Options) driver() string { return "sqlite" }
+func (o *Options) driver() string {
+	if o == nil || o.Driver == "" {
+		return "sqlite"
+	}
+	return o.Driver
+}
 
 // A Store connects to a collection of cookies stored in an SQLite database
 // using the Google Chrome cookie schema.
@@ -115,64 +199,100 @@ type Store struct {
 	db        *sql.DB
 	key       []byte // encryption key, or nil
 	dbVersion int    // from the meta table
+	readOnly  bool
+	ownDB     bool             // whether Close should close db
+	snap      *dbfile.Snapshot // if opened from a snapshot
 }
 
 // Scan satisfies part of the [cookies.Store] interface.
 func (s *Store) Scan(f cookies.ScanFunc) error {
-	cs, err := s.readCookies()
-	if err != nil {
-		return err
-	}
+	return s.scanWhere(context.Background(), cookies.Query{}, f)
+}
 
-	tx, err := s.db.Begin()
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
+// ScanContext satisfies the [cookies.ContextStore] interface.  If ctx ends
+// before the scan is complete, the scan stops, no changes are applied, and
+// ScanContext reports the error from ctx.
+func (s *Store) ScanContext(ctx context.Context, f cookies.ScanFunc) error {
+	return s.scanWhere(ctx, cookies.Query{}, f)
+}
 
-	for _, c := range cs {
-		act, err := f(c)
-		if err != nil {
-			return err
-		}
-		switch act {
-		case cookies.Keep:
-			continue
+// All satisfies the [cookies.IterStore] interface.  Cookies are read in batches
+// of increasing row ID within a read-only transaction.
+func (s *Store) All() iter.Seq2[cookies.C, error] { return s.table().All() }
 
-		case cookies.Update:
-			if err := s.writeCookie(tx, c); err != nil {
-				return err
-			}
-		case cookies.Discard:
-			if err := s.dropCookie(tx, c); err != nil {
-				return err
-			}
-		default:
-			return fmt.Errorf("unknown action %v", act)
-		}
+// ScanWhere satisfies the [cookies.FilteredStore] interface.  The conditions
+// of q are evaluated by the database, so only matching cookies are read and
+// decrypted.
+//
+// Rows are read and decrypted a batch at a time, so a profile with many
+// cookies is not held in memory at once. All changes are applied in a single
+// transaction, which is committed only if the scan completes without error.
+func (s *Store) ScanWhere(q cookies.Query, f cookies.ScanFunc) error {
+	return s.scanWhere(context.Background(), q, f)
+}
+
+func (s *Store) scanWhere(ctx context.Context, q cookies.Query, f cookies.ScanFunc) error {
+	return s.table().Scan(ctx, q, f)
+}
+
+// table returns a scanner for the cookies table of s.
+func (s *Store) table() *dbfile.Table[*Cookie] {
+	return &dbfile.Table[*Cookie]{
+		DB:       s.db,
+		ReadOnly: s.readOnly,
+		Filter: dbfile.Filter{
+			Host:         "host_key",
+			Name:         "name",
+			Expires:      "expires_utc",
+			ExpiresValue: timeToTimestamp,
+			ZeroSession:  true, // session cookies have expires_utc = 0
+		},
+		Read:  s.readCookies,
+		ID:    func(c *Cookie) int64 { return c.rowID },
+		Write: s.writeCookie,
+		Drop:  s.dropCookie,
 	}
-	return tx.Commit()
 }
 
+// ReadOnly reports whether s was opened read-only, either by the ReadOnly
+// option or from a snapshot. Changes to a read-only store report an error.
+func (s *Store) ReadOnly() bool { return s.readOnly }
+
 // Commit satisfies part of the [cookies.Store] interface.
 // In this implementation it is a no-op without error.
 func (s *Store) Commit() error { return nil }
 
-// readCookies reads all the cookies in the database.
-func (s *Store) readCookies() ([]*Cookie, error) {
-	rows, err := s.db.Query(readCookiesStmt)
+// Close closes the database, and removes its snapshot if it has one.  If s was
+// created by OpenDB, Close does not close the database.
+func (s *Store) Close() error {
+	var err error
+	if s.ownDB {
+		err = s.db.Close()
+	}
+	if s.snap != nil {
+		err = errors.Join(err, s.snap.Close())
+	}
+	return err
+}
+
+// readCookies reads up to limit cookies satisfying the SQL conditions in conds
+// whose row IDs are greater than after, in order of increasing row ID.
+func (s *Store) readCookies(ctx context.Context, tx *sql.Tx, after int64, limit int, conds []string, args []any) ([]*Cookie, error) {
+	query := readCookiesStmt + "\nWHERE " + strings.Join(append([]string{"rowid > ?"}, conds...), " AND ") +
+		"\nORDER BY rowid LIMIT ?"
+	rows, err := tx.QueryContext(ctx, query, append(append([]any{after}, args...), limit)...)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	var cs []*Cookie
 	for rows.Next() {
-		var rowID, expiresUTC, creationUTC, isSecure, isHTTPOnly, sameSite int64
+		var rowID, expiresUTC, creationUTC, isSecure, isHTTPOnly, sameSite, priority int64
 		var name, value, hostKey, path string
 		var encValue, hostHash []byte
 		if err := rows.Scan(&rowID, &name, &value, &encValue, &hostKey, &path,
-			&expiresUTC, &creationUTC, &isSecure, &isHTTPOnly, &sameSite); err != nil {
-			rows.Close()
+			&expiresUTC, &creationUTC, &isSecure, &isHTTPOnly, &sameSite, &priority); err != nil {
 			return nil, err
 		}
 
@@ -196,59 +316,69 @@ func (s *Store) readCookies() ([]*Cookie, error) {
 			}
 		}
 
+		domain, hostOnly := cookies.ParseHostKey(hostKey)
 		cs = append(cs, &Cookie{
 			C: cookies.C{
 				Name:    name,
 				Value:   value,
-				Domain:  hostKey,
+				Domain:  domain,
 				Path:    path,
 				Expires: timestampToTime(expiresUTC),
 				Created: timestampToTime(creationUTC),
 				Flags: cookies.Flags{
 					Secure:   isSecure != 0,
 					HTTPOnly: isHTTPOnly != 0,
+					HostOnly: hostOnly,
 				},
 				SameSite: decodeSitePolicy(sameSite),
 			},
+			priority: priority,
 			rowID:    rowID,
 			hostHash: hostHash, // if present
 		})
 	}
-	return cs, nil
+	return cs, rows.Err()
 }
 
 // dropCookie deletes c from the database.
-func (s *Store) dropCookie(tx *sql.Tx, c *Cookie) error {
-	_, err := tx.Exec(dropCookieStmt, sql.Named("rowid", c.rowID))
-	return err
+func (s *Store) dropCookie(w *dbfile.Writer, c *Cookie) error {
+	return w.Exec(dropCookieStmt, sql.Named("rowid", c.rowID))
 }
 
 // writeCookie writes the current state of c to the store.
-func (s *Store) writeCookie(tx *sql.Tx, c *Cookie) error {
-	var column, query string
-	var value any
+func (s *Store) writeCookie(w *dbfile.Writer, c *Cookie) error {
+	column, value, err := s.encodeValue(c.C)
+	if err != nil {
+		return err
+	}
+	return w.Exec(fmt.Sprintf(writeCookieStmt, column), append(cookieArgs(c.C, value),
+		sql.Named("priority", c.priority), sql.Named("rowid", c.rowID))...)
+}
+
+// encodeValue returns the column in which the value of c should be stored,
+// and the value to store there, encrypting it if s has an encryption key.
+func (s *Store) encodeValue(c cookies.C) (string, any, error) {
 	if len(s.key) == 0 {
-		column = "value"
-		value = c.Value
-	} else {
-		vbytes := []byte(c.Value)
-		if s.dbVersion >= minHashKeyVersion {
-			hostHash := sha256.Sum256([]byte(c.Domain))
-			vbytes = append(hostHash[:], vbytes...)
-		}
-		if enc, err := encryptValue(s.key, vbytes); err != nil {
-			return fmt.Errorf("encrypting value: %w", err)
-		} else {
-			column = "encrypted_value"
-			value = enc
-		}
+		return "value", c.Value, nil
+	}
+	vbytes := []byte(c.Value)
+	if s.dbVersion >= minHashKeyVersion {
+		hostHash := sha256.Sum256([]byte(c.HostKey()))
+		vbytes = append(hostHash[:], vbytes...)
 	}
-	query = fmt.Sprintf(writeCookieStmt, column)
+	enc, err := encryptValue(s.key, vbytes)
+	if err != nil {
+		return "", nil, fmt.Errorf("encrypting value: %w", err)
+	}
+	return "encrypted_value", enc, nil
+}
 
-	_, err := tx.Exec(query,
-		sql.Named("rowid", c.rowID),
+// cookieArgs returns the named arguments for updateCookieStmt to store c,
+// with the given encoded value.
+func cookieArgs(c cookies.C, value any) []any {
+	return []any{
 		sql.Named("name", c.Name),
-		sql.Named("host", c.Domain),
+		sql.Named("host", c.HostKey()),
 		sql.Named("path", c.Path),
 		sql.Named("expires", timeToTimestamp(c.Expires)),
 		sql.Named("created", timeToTimestamp(c.Created)),
@@ -256,8 +386,7 @@ func (s *Store) writeCookie(tx *sql.Tx, c *Cookie) error {
 		sql.Named("httponly", boolToInt(c.Flags.HTTPOnly)),
 		sql.Named("samesite", encodeSitePolicy(c.SameSite)),
 		sql.Named("value", value),
-	)
-	return err
+	}
 }
 
 // A Cookie represents a single cookie from a Chrome database.
@@ -266,9 +395,15 @@ func (s *Store) writeCookie(tx *sql.Tx, c *Cookie) error {
 // encryption key. If no decryption key is provided, encrypted values are
 // represented by a Value with string "[ENCRYPTED]"; if an invalid decryption
 // key is given, an error is reported.
+//
+// A Cookie also satisfies the [cookies.AttrEditor] interface, with the
+// following Chrome-specific attributes:
+//
+//	priority -- int64, the eviction priority (0 low, 1 medium, 2 high)
 type Cookie struct {
 	cookies.C
 
+	priority int64
 	rowID    int64
 	hostHash []byte // for versions > 23
 }
@@ -277,7 +412,34 @@ type Cookie struct {
 func (c *Cookie) Get() cookies.C { return c.C }
 
 // Set satisfies part of the [cookies.Editor] interface.
-func (c *Cookie) Set(o cookies.C) error { c.C = o; return nil }
+// It reports an error if o is not valid according to [cookies.Validate].
+func (c *Cookie) Set(o cookies.C) error {
+	if err := cookies.Validate(o); err != nil {
+		return err
+	}
+	c.C = o
+	return nil
+}
+
+// Attrs satisfies part of the [cookies.AttrEditor] interface.
+func (c *Cookie) Attrs() map[string]any { return map[string]any{"priority": c.priority} }
+
+// SetAttr satisfies part of the [cookies.AttrEditor] interface.
+func (c *Cookie) SetAttr(name string, value any) error {
+	switch name {
+	case "priority":
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("attribute %q: got %T, want int64", name, value)
+		} else if v < 0 || v > 2 {
+			return fmt.Errorf("attribute %q: value %d out of range", name, v)
+		}
+		c.priority = v
+		return nil
+	default:
+		return fmt.Errorf("unknown attribute %q", name)
+	}
+}
 
 // decodeSitePolicy maps a Chrome SameSite policy to the generic enum.
 func decodeSitePolicy(v int64) cookies.SameSite {
@@ -308,15 +470,22 @@ func encodeSitePolicy(p cookies.SameSite) int64 {
 }
 
 // timestampToTime converts a value in microseconds sincde the Chrome epoch to
-// a time in UTC.
+// a time in UTC. The value 0, meaning no expiration, is the zero time.
 func timestampToTime(usec int64) time.Time {
+	if usec == 0 {
+		return time.Time{}
+	}
 	sec := usec/1e6 - chromeEpoch
 	nano := (usec % 1e6) * 1000
 	return time.Unix(sec, nano).In(time.UTC)
 }
 
 // timeToTimestamp conversts a time value to microseconds since the Chrome epoch.
+// The zero time, meaning no expiration, is represented as 0.
 func timeToTimestamp(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
 	sec := t.Unix() + chromeEpoch
 	usec := int64(t.Nanosecond()) / 1000
 	return sec*1e6 + usec
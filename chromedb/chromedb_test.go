@@ -15,11 +15,22 @@
 package chromedb_test
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/creachadair/cookies"
 	"github.com/creachadair/cookies/chromedb"
+	"github.com/creachadair/cookies/internal/dbfile/dbtest"
+	"github.com/google/go-cmp/cmp"
 
 	_ "modernc.org/sqlite"
 )
@@ -67,3 +78,520 @@ func TestManual(t *testing.T) {
 
 	t.Logf("Found %d cookies", numCookies)
 }
+
+// schema is the Chrome cookie schema, with a meta table of version 24.
+var schema = []string{
+	`CREATE TABLE meta (key LONGVARCHAR NOT NULL UNIQUE PRIMARY KEY, value LONGVARCHAR)`,
+	`INSERT INTO meta (key, value) VALUES ('version', '24')`,
+	`CREATE TABLE cookies (
+  creation_utc INTEGER NOT NULL, host_key TEXT NOT NULL, top_frame_site_key TEXT NOT NULL DEFAULT '',
+  name TEXT NOT NULL, value TEXT NOT NULL, encrypted_value BLOB NOT NULL DEFAULT x'',
+  path TEXT NOT NULL, expires_utc INTEGER NOT NULL, is_secure INTEGER NOT NULL,
+  is_httponly INTEGER NOT NULL, last_access_utc INTEGER NOT NULL DEFAULT 0,
+  has_expires INTEGER NOT NULL DEFAULT 1, is_persistent INTEGER NOT NULL DEFAULT 1,
+  priority INTEGER NOT NULL DEFAULT 1, samesite INTEGER NOT NULL DEFAULT -1,
+  source_scheme INTEGER NOT NULL DEFAULT 0, source_port INTEGER NOT NULL DEFAULT -1,
+  last_update_utc INTEGER NOT NULL DEFAULT 0, source_type INTEGER NOT NULL DEFAULT 0,
+  has_cross_site_ancestor INTEGER NOT NULL DEFAULT 0)`,
+}
+
+// newTestDB creates a Chrome cookie database in a temporary directory,
+// populated with the given cookies, and returns its path. Values are stored
+// unencrypted.
+func newTestDB(t testing.TB, cs ...cookies.C) string {
+	t.Helper()
+	path := dbtest.NewDB(t, "Cookies", schema...)
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("Open test database: %v", err)
+	}
+	defer db.Close()
+	insertCookies(t, db, cs...)
+	return path
+}
+
+// initTestDB creates the cookie schema in db and populates it with cs.
+func initTestDB(t testing.TB, db *sql.DB, cs ...cookies.C) {
+	t.Helper()
+	for _, stmt := range schema {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("Create schema: %v", err)
+		}
+	}
+	insertCookies(t, db, cs...)
+}
+
+// insertCookies adds cs to the cookies table of db.
+func insertCookies(t testing.TB, db *sql.DB, cs ...cookies.C) {
+	t.Helper()
+	for _, c := range cs {
+		if _, err := db.Exec(`INSERT INTO cookies `+
+			`(name, value, host_key, path, expires_utc, creation_utc, is_secure, is_httponly, samesite) `+
+			`VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			c.Name, c.Value, c.HostKey(), c.Path, chromeTime(c.Expires), chromeTime(c.Created),
+			c.Flags.Secure, c.Flags.HTTPOnly, sameSiteValue[c.SameSite]); err != nil {
+			t.Fatalf("Insert cookie: %v", err)
+		}
+	}
+}
+
+// chromeTime converts t to microseconds since the Chrome epoch.
+func chromeTime(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMicro() + 11644473600e6
+}
+
+// sameSiteValue maps generic SameSite policies to Chrome values.
+var sameSiteValue = map[cookies.SameSite]int{
+	cookies.Unknown: -1, cookies.None: 0, cookies.Lax: 1, cookies.Strict: 2,
+}
+
+func TestReadOnly(t *testing.T) {
+	path := newTestDB(t,
+		cookies.C{Name: "a", Domain: ".example.com", Path: "/"},
+		cookies.C{Name: "b", Domain: "example.com", Path: "/"},
+	)
+	discard := func(cookies.Editor) (cookies.Action, error) { return cookies.Discard, nil }
+
+	for _, opts := range []*chromedb.Options{{ReadOnly: true}, {Snapshot: true}} {
+		s, err := chromedb.Open(path, opts)
+		if err != nil {
+			t.Fatalf("Open %+v: %v", opts, err)
+		}
+		if diff := cmp.Diff([]string{"a", "b"}, dbtest.ScanNames(t, s)); diff != "" {
+			t.Errorf("Names %+v: (-want, +got)\n%s", opts, diff)
+		}
+		if err := s.Scan(discard); err == nil {
+			t.Errorf("Scan %+v with Discard: got nil, want error", opts)
+		}
+		if err := s.Close(); err != nil {
+			t.Errorf("Close %+v: %v", opts, err)
+		}
+	}
+
+	// The original database should be unaffected.
+	s, err := chromedb.Open(path, nil)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+	if diff := cmp.Diff([]string{"a", "b"}, dbtest.ScanNames(t, s)); diff != "" {
+		t.Errorf("Names: (-want, +got)\n%s", diff)
+	}
+}
+
+func TestLock(t *testing.T) {
+	path := newTestDB(t, cookies.C{Name: "a", Domain: "example.com", Path: "/"})
+	host, err := os.Hostname()
+	if err != nil {
+		t.Fatalf("Hostname: %v", err)
+	}
+	lock := filepath.Join(filepath.Dir(path), "SingletonLock")
+	setLock := func(target string) {
+		t.Helper()
+		os.Remove(lock)
+		if err := os.Symlink(target, lock); err != nil {
+			t.Fatalf("Create lock: %v", err)
+		}
+	}
+
+	// A lock held by a live process, or by a process on another host, blocks.
+	for _, target := range []string{
+		fmt.Sprintf("%s-%d", host, os.Getpid()),
+		"some.other.host-12345",
+	} {
+		setLock(target)
+		if s, err := chromedb.Open(path, nil); !errors.Is(err, cookies.ErrLocked) {
+			t.Errorf("Open with lock %q: got (%v, %v), want %v", target, s, err, cookies.ErrLocked)
+		}
+	}
+	for _, opts := range []*chromedb.Options{{ReadOnly: true}, {IgnoreLock: true}} {
+		s, err := chromedb.Open(path, opts)
+		if err != nil {
+			t.Errorf("Open %+v: unexpected error: %v", opts, err)
+		} else {
+			s.Close()
+		}
+	}
+
+	// A lock left by a process that has exited does not block.
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Run subprocess: %v", err)
+	}
+	setLock(fmt.Sprintf("%s-%d", host, cmd.Process.Pid))
+	if s, err := chromedb.Open(path, nil); err != nil {
+		t.Errorf("Open with stale lock: unexpected error: %v", err)
+	} else {
+		s.Close()
+	}
+
+	os.Remove(lock)
+	s, err := chromedb.Open(path, nil)
+	if err != nil {
+		t.Fatalf("Open without lock: unexpected error: %v", err)
+	}
+	s.Close()
+}
+
+func TestOpenDB(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("Open database: %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1) // each connection has its own in-memory database
+	initTestDB(t, db, cookies.C{Name: "a", Domain: "example.com", Path: "/"})
+
+	s, err := chromedb.OpenDB(db, nil)
+	if err != nil {
+		t.Fatalf("OpenDB: %v", err)
+	}
+	if diff := cmp.Diff([]string{"a"}, dbtest.ScanNames(t, s)); diff != "" {
+		t.Errorf("Names: (-want, +got)\n%s", diff)
+	}
+	if err := s.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+
+	// Closing the store should not close the caller's database.
+	if err := db.Ping(); err != nil {
+		t.Errorf("Ping after Close: %v", err)
+	}
+
+	// An unregistered driver should be reported.
+	path := filepath.Join(t.TempDir(), "Cookies")
+	if s, err := chromedb.Open(path, &chromedb.Options{Driver: "nonesuch"}); err == nil {
+		t.Errorf("Open with bad driver: got %v, want error", s)
+	}
+}
+
+func TestScanWhere(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	all := []cookies.C{
+		{Name: "a", Domain: ".example.com", Path: "/", Expires: now.Add(time.Hour)},
+		{Name: "b", Domain: "www.example.com", Path: "/", Expires: now.Add(-time.Hour)},
+		{Name: "a", Domain: "badexample.com", Path: "/", Expires: now.Add(time.Hour)},
+		{Name: "c", Domain: "ex_mple.com", Path: "/", Expires: now.Add(time.Hour)},
+		{Name: "d", Domain: "example.com", Path: "/"}, // session cookie
+	}
+	s, err := chromedb.Open(newTestDB(t, all...), nil)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+
+	for _, q := range []cookies.Query{
+		{},
+		{Domain: "example.com"},
+		{Domain: "www.example.com"},
+		{Domain: "ex_mple.com"},
+		{Name: "a"},
+		{Domain: "example.com", Name: "a"},
+		{ExpiresBefore: now},
+		{ExpiresAfter: now},
+	} {
+		var want, got []string
+		for _, c := range all {
+			if q.Match(c) {
+				want = append(want, c.HostKey()+":"+c.Name)
+			}
+		}
+		if err := s.ScanWhere(q, func(e cookies.Editor) (cookies.Action, error) {
+			c := e.Get()
+			got = append(got, c.HostKey()+":"+c.Name)
+			return cookies.Keep, nil
+		}); err != nil {
+			t.Fatalf("ScanWhere %+v: %v", q, err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("ScanWhere %+v: (-want, +got)\n%s", q, diff)
+		}
+	}
+}
+
+// newLargeDB creates a Chrome cookie database with n generated cookies, and
+// returns its path.
+func newLargeDB(t testing.TB, n int) string {
+	t.Helper()
+	path := newTestDB(t)
+	now := time.Now()
+	dbtest.Fill(t, path, n, `INSERT INTO cookies `+
+		`(name, value, host_key, path, expires_utc, creation_utc, is_secure, is_httponly) `+
+		`VALUES (?, ?, ?, '/', ?, ?, 0, 0)`,
+		func(i int) []any {
+			return []any{fmt.Sprintf("name%d", i), fmt.Sprintf("value%d", i),
+				fmt.Sprintf(".host%d.example.com", i%1000), chromeTime(now.Add(time.Hour)), chromeTime(now)}
+		})
+	return path
+}
+
+func BenchmarkScan(b *testing.B) {
+	dbtest.BenchmarkScan(b, newLargeDB(b, 100_000), "cookies", func(path string) (dbtest.Store, error) {
+		return chromedb.Open(path, nil)
+	})
+}
+
+func TestScanBatches(t *testing.T) {
+	const numCookies = 2500 // more than one batch
+	s, err := chromedb.Open(newLargeDB(t, numCookies), nil)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+	dbtest.ScanBatches(t, s, numCookies)
+}
+
+func TestKeyed(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	a := cookies.C{Name: "a", Value: "1", Domain: "example.com", Path: "/", Expires: now.Add(time.Hour), Created: now, SameSite: cookies.Lax}
+	b := cookies.C{Name: "b", Value: "2", Domain: "example.com", Path: "/", Expires: now.Add(time.Hour), Created: now, SameSite: cookies.Lax}
+	s, err := chromedb.Open(newTestDB(t, a), nil)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+	var _ cookies.KeyedStore = s
+
+	mustGet := func(key cookies.Key, want cookies.C) {
+		t.Helper()
+		got, err := s.Get(key)
+		if err != nil {
+			t.Fatalf("Get %v: unexpected error: %v", key, err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("Get %v: (-want, +got)\n%s", key, diff)
+		}
+	}
+
+	mustGet(a.Key(), a)
+	if _, err := s.Get(b.Key()); !errors.Is(err, cookies.ErrNotFound) {
+		t.Errorf("Get %v: got %v, want %v", b.Key(), err, cookies.ErrNotFound)
+	}
+
+	// Put of a new key inserts a cookie.
+	if err := s.Put(b); err != nil {
+		t.Fatalf("Put %v: %v", b.Key(), err)
+	}
+	mustGet(b.Key(), b)
+
+	// Put of an existing key replaces the cookie.
+	a.Value = "updated"
+	a.Flags.Secure = true
+	a.SameSite = cookies.Strict
+	if err := s.Put(a); err != nil {
+		t.Fatalf("Put %v: %v", a.Key(), err)
+	}
+	mustGet(a.Key(), a)
+	if diff := cmp.Diff([]string{"a", "b"}, dbtest.ScanNames(t, s)); diff != "" {
+		t.Errorf("Names after Put (-want, +got):\n%s", diff)
+	}
+
+	if err := s.Delete(a.Key()); err != nil {
+		t.Fatalf("Delete %v: %v", a.Key(), err)
+	}
+	if err := s.Delete(a.Key()); err != nil {
+		t.Errorf("Delete %v again: %v", a.Key(), err)
+	}
+	if diff := cmp.Diff([]string{"b"}, dbtest.ScanNames(t, s)); diff != "" {
+		t.Errorf("Names after Delete (-want, +got):\n%s", diff)
+	}
+}
+
+func TestScanContext(t *testing.T) {
+	s, err := chromedb.Open(newTestDB(t,
+		cookies.C{Name: "a", Domain: "example.com", Path: "/"},
+		cookies.C{Name: "b", Domain: "example.com", Path: "/"},
+		cookies.C{Name: "c", Domain: "example.com", Path: "/"},
+	), nil)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+	var _ cookies.ContextStore = s
+
+	// Cancel the scan partway through. None of the discards should be applied.
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	var n int
+	err = s.ScanContext(ctx, func(e cookies.Editor) (cookies.Action, error) {
+		if n++; n == 2 {
+			cancel()
+		}
+		return cookies.Discard, nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("ScanContext: got error %v, want %v", err, context.Canceled)
+	}
+	if n != 2 {
+		t.Errorf("ScanContext: visited %d cookies, want 2", n)
+	}
+	if diff := cmp.Diff([]string{"a", "b", "c"}, dbtest.ScanNames(t, s)); diff != "" {
+		t.Errorf("Names after cancel (-want, +got):\n%s", diff)
+	}
+}
+
+func TestAll(t *testing.T) {
+	s, err := chromedb.Open(newTestDB(t,
+		cookies.C{Name: "a", Domain: "example.com", Path: "/"},
+		cookies.C{Name: "b", Domain: "example.com", Path: "/"},
+		cookies.C{Name: "c", Domain: "example.com", Path: "/"},
+	), &chromedb.Options{ReadOnly: true})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+	var _ cookies.IterStore = s
+
+	var got []string
+	for c, err := range s.All() {
+		if err != nil {
+			t.Fatalf("All: unexpected error: %v", err)
+		}
+		got = append(got, c.Name)
+		if c.Name == "b" {
+			break
+		}
+	}
+	if diff := cmp.Diff([]string{"a", "b"}, got); diff != "" {
+		t.Errorf("All (-want, +got):\n%s", diff)
+	}
+}
+
+func TestAttrs(t *testing.T) {
+	s, err := chromedb.Open(newTestDB(t, cookies.C{Name: "a", Domain: "example.com", Path: "/"}), nil)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+
+	if err := s.Scan(func(e cookies.Editor) (cookies.Action, error) {
+		ae, ok := e.(cookies.AttrEditor)
+		if !ok {
+			t.Fatalf("Editor %T does not implement AttrEditor", e)
+		}
+		if diff := cmp.Diff(map[string]any{"priority": int64(1)}, ae.Attrs()); diff != "" {
+			t.Errorf("Attrs (-want, +got):\n%s", diff)
+		}
+		if err := ae.SetAttr("nonesuch", 1); err == nil {
+			t.Error("SetAttr unknown name: got nil, want error")
+		}
+		if err := ae.SetAttr("priority", true); err == nil {
+			t.Error("SetAttr wrong type: got nil, want error")
+		}
+		if err := ae.SetAttr("priority", int64(2)); err != nil {
+			t.Errorf("SetAttr: unexpected error: %v", err)
+		}
+		return cookies.Update, nil
+	}); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+
+	// Verify that the update was stored.
+	if err := s.Scan(func(e cookies.Editor) (cookies.Action, error) {
+		got := e.(cookies.AttrEditor).Attrs()
+		if diff := cmp.Diff(map[string]any{"priority": int64(2)}, got); diff != "" {
+			t.Errorf("Attrs after update (-want, +got):\n%s", diff)
+		}
+		return cookies.Keep, nil
+	}); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+}
+
+func TestHostOnly(t *testing.T) {
+	path := newTestDB(t,
+		cookies.C{Name: "a", Domain: "example.com", Path: "/"},
+		cookies.C{Name: "b", Domain: "www.example.com", Path: "/", Flags: cookies.Flags{HostOnly: true}},
+	)
+	s, err := chromedb.Open(path, nil)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+
+	// Update every cookie without changes, and verify that the stored host
+	// keys preserve the scope of each cookie.
+	if err := s.Scan(func(e cookies.Editor) (cookies.Action, error) {
+		return cookies.Update, e.Set(e.Get())
+	}); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("Open database: %v", err)
+	}
+	defer db.Close()
+	var got []string
+	rows, err := db.Query(`SELECT host_key FROM cookies ORDER BY name`)
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			t.Fatalf("Scan row: %v", err)
+		}
+		got = append(got, key)
+	}
+	if diff := cmp.Diff([]string{".example.com", "www.example.com"}, got); diff != "" {
+		t.Errorf("Host keys (-want, +got):\n%s", diff)
+	}
+}
+
+func TestSessionCookie(t *testing.T) {
+	now := time.Now().Truncate(time.Second).UTC()
+	a := cookies.C{Name: "a", Value: "1", Domain: "example.com", Path: "/", Created: now}
+	b := cookies.C{Name: "b", Value: "2", Domain: "example.com", Path: "/", Created: now}
+	s, err := chromedb.Open(newTestDB(t, a), nil)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+
+	// A session cookie stored with expires_utc = 0 has no expiration.
+	got, err := s.Get(a.Key())
+	if err != nil {
+		t.Fatalf("Get %v: %v", a.Key(), err)
+	}
+	if !got.Expires.IsZero() {
+		t.Errorf("Get %v: expires %v, want zero", a.Key(), got.Expires)
+	}
+
+	// A session cookie written to the store reads back unchanged.
+	if err := s.Put(b); err != nil {
+		t.Fatalf("Put %v: %v", b.Key(), err)
+	}
+	got, err = s.Get(b.Key())
+	if err != nil {
+		t.Fatalf("Get %v: %v", b.Key(), err)
+	}
+	if diff := cmp.Diff(b, got); diff != "" {
+		t.Errorf("Get %v: (-want, +got)\n%s", b.Key(), diff)
+	}
+}
+
+func TestForURL(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	s, err := chromedb.Open(newTestDB(t,
+		cookies.C{Name: "session", Value: "1", Domain: ".example.com", Path: "/", Created: now},
+		cookies.C{Name: "live", Value: "2", Domain: ".example.com", Path: "/", Created: now.Add(time.Second), Expires: now.Add(time.Hour)},
+		cookies.C{Name: "dead", Value: "3", Domain: ".example.com", Path: "/", Created: now, Expires: now.Add(-time.Hour)},
+	), &chromedb.Options{ReadOnly: true})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+
+	u, _ := url.Parse("https://www.example.com/")
+	cs, err := cookies.ForURL(s, u)
+	if err != nil {
+		t.Fatalf("ForURL %v: %v", u, err)
+	}
+	if got, want := cookies.CookieHeader(cs), "session=1; live=2"; got != want {
+		t.Errorf("CookieHeader: got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chromedb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+
+	"github.com/creachadair/cookies"
+	"github.com/creachadair/cookies/internal/dbfile"
+)
+
+// Get implements part of the [cookies.KeyedStore] interface.
+func (s *Store) Get(key cookies.Key) (cookies.C, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return cookies.C{}, err
+	}
+	defer tx.Rollback()
+
+	cs, err := s.readCookies(context.Background(), tx, math.MinInt64, 1,
+		[]string{"host_key = ?", "name = ?", "path = ?"}, []any{key.Domain, key.Name, key.Path})
+	if err != nil {
+		return cookies.C{}, err
+	} else if len(cs) == 0 {
+		return cookies.C{}, cookies.ErrNotFound
+	}
+	return cs[0].C, nil
+}
+
+// Put implements part of the [cookies.KeyedStore] interface. The change takes
+// effect immediately.
+//
+// If the database has no cookie with the key of c, Put inserts a new row,
+// filling in the Chrome-specific columns with default values.
+func (s *Store) Put(c cookies.C) error {
+	if s.readOnly {
+		return dbfile.ErrReadOnly
+	}
+	if err := cookies.Validate(c); err != nil {
+		return err
+	}
+	column, value, err := s.encodeValue(c)
+	if err != nil {
+		return err
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	args := cookieArgs(c, value)
+	res, err := tx.Exec(fmt.Sprintf(putCookieStmt, column), args...)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		if err := insertCookie(tx, c, column, value); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// insertCookie adds a new row for c to the cookies table, storing the encoded
+// value in the specified column.
+func insertCookie(tx *sql.Tx, c cookies.C, column string, value any) error {
+	created := timeToTimestamp(c.Created)
+	persistent := boolToInt(!c.Expires.IsZero())
+	values := map[string]any{
+		"creation_utc":            created,
+		"host_key":                c.HostKey(),
+		"top_frame_site_key":      "",
+		"name":                    c.Name,
+		"value":                   "",
+		"encrypted_value":         []byte{},
+		"path":                    c.Path,
+		"expires_utc":             timeToTimestamp(c.Expires),
+		"is_secure":               boolToInt(c.Flags.Secure),
+		"is_httponly":             boolToInt(c.Flags.HTTPOnly),
+		"last_access_utc":         created,
+		"has_expires":             persistent,
+		"is_persistent":           persistent,
+		"priority":                1, // medium
+		"samesite":                encodeSitePolicy(c.SameSite),
+		"source_scheme":           0, // unset
+		"source_port":             -1,
+		"last_update_utc":         created,
+		"source_type":             0, // unknown
+		"has_cross_site_ancestor": 0,
+	}
+	values[column] = value
+	return dbfile.Insert(tx, "cookies", values)
+}
+
+// Delete implements part of the [cookies.KeyedStore] interface. The change
+// takes effect immediately.
+func (s *Store) Delete(key cookies.Key) error {
+	if s.readOnly {
+		return dbfile.ErrReadOnly
+	}
+	_, err := s.db.Exec(deleteKeyStmt,
+		sql.Named("host", key.Domain), sql.Named("name", key.Name), sql.Named("path", key.Path))
+	return err
+}
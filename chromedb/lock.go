@@ -0,0 +1,72 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chromedb
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/creachadair/cookies"
+	"github.com/creachadair/cookies/internal/dbfile"
+)
+
+// lockFiles are the names of the files Chrome keeps in its user data
+// directory while it is running: SingletonLock is a symbolic link used on
+// macOS and Linux, lockfile is used on Windows.
+var lockFiles = []string{"SingletonLock", "lockfile"}
+
+// checkLock reports an error wrapping [cookies.ErrLocked] if the browser that
+// owns the cookie database at path appears to be running.
+//
+// The database is stored in a profile directory (or its Network subdirectory),
+// and the lock is in the user data directory that contains the profiles, so
+// the search looks a few levels up from the database. A lock left behind by
+// a browser that exited without removing it is ignored.
+func checkLock(path string) error {
+	dir := filepath.Dir(path)
+	for range 3 {
+		for _, name := range lockFiles {
+			if lock := filepath.Join(dir, name); dbfile.Exists(lock) && !isStale(lock) {
+				return fmt.Errorf("%w: found %s", cookies.ErrLocked, lock)
+			}
+		}
+		dir = filepath.Dir(dir)
+	}
+	return nil
+}
+
+// isStale reports whether lock is a SingletonLock link whose target names a
+// process on this host that is no longer running. The target of the link has
+// the form "hostname-pid". A lock held by another host is never stale, since
+// there is no way to check whether its process is running.
+func isStale(lock string) bool {
+	target, err := os.Readlink(lock)
+	if err != nil {
+		return false
+	}
+	i := strings.LastIndexByte(target, '-')
+	if i < 0 {
+		return false
+	}
+	host, err := os.Hostname()
+	if err != nil || target[:i] != host {
+		return false
+	}
+	pid, err := strconv.Atoi(target[i+1:])
+	return err == nil && dbfile.ProcessExited(pid)
+}
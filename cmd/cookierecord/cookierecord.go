@@ -0,0 +1,158 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Program cookierecord runs a local HTTP proxy that records the cookies set
+// by the sites it proxies into a cookie store.
+//
+// By default the proxy is a forward proxy for plain HTTP. Point a client at
+// it with the HTTP_PROXY environment variable:
+//
+//	cookierecord -log - fixture.json &
+//	HTTP_PROXY=http://localhost:8080 ./scripted-session.sh
+//
+// To record an HTTPS site, use -target to forward all requests to the site,
+// and direct the client to the proxy's address instead of the site:
+//
+//	cookierecord -target https://example.com fixture.json
+//
+// The store is a Playwright storage state file if its name ends in ".json",
+// and is created if it does not exist. Otherwise it is any browser store
+// understood by washcookies.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	"github.com/creachadair/cookies"
+	"github.com/creachadair/cookies/internal/openstore"
+	"github.com/creachadair/cookies/record"
+	"github.com/creachadair/cookies/storagestate"
+
+	// Import SQLite3 driver for database/sql.
+	_ "modernc.org/sqlite"
+)
+
+var (
+	listenAddr = flag.String("listen", "localhost:8080", "Address to listen on")
+	targetURL  = flag.String("target", "", "If set, forward all requests to this base URL")
+	logPath    = flag.String("log", "", `Write a log of cookie changes to this file ("-" for stderr)`)
+)
+
+func init() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: %s [options] cookie-file
+
+Run an HTTP proxy that records the cookies set by proxied responses into
+the specified cookie file. If the file name ends in ".json", it is a
+Playwright storage state file, which is created if necessary. Otherwise
+it must be an existing browser cookie store.
+
+Options:
+`, filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+}
+
+func main() {
+	flag.Parse()
+	if flag.NArg() != 1 {
+		log.Fatal("You must provide exactly one cookie file")
+	}
+	if err := run(flag.Arg(0)); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run records cookies into the store at path until the server is stopped by
+// an interrupt, then commits the store.
+func run(path string) error {
+	opts := new(record.Options)
+	if *targetURL != "" {
+		u, err := url.Parse(*targetURL)
+		if err != nil || !u.IsAbs() {
+			return fmt.Errorf("invalid -target URL %q", *targetURL)
+		}
+		opts.Target = u
+	}
+	switch *logPath {
+	case "":
+	case "-":
+		opts.Log = os.Stderr
+	default:
+		f, err := os.OpenFile(*logPath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+		if err != nil {
+			return fmt.Errorf("opening log: %w", err)
+		}
+		defer f.Close()
+		opts.Log = f
+	}
+
+	s, err := openStore(path)
+	if errors.Is(err, cookies.ErrLocked) {
+		return fmt.Errorf("opening %q: %w (is the browser running?)", path, err)
+	} else if err != nil {
+		return fmt.Errorf("opening %q: %w", path, err)
+	}
+	if c, ok := s.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	srv := &http.Server{Addr: *listenAddr, Handler: record.New(s, opts)}
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		srv.Shutdown(context.Background())
+	}()
+
+	fmt.Fprintf(os.Stderr, "Recording cookies into %q via http://%s\n", path, *listenAddr)
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("server: %w", err)
+	}
+	if err := s.Commit(); err != nil {
+		return fmt.Errorf("committing %q: %w", path, err)
+	}
+	return nil
+}
+
+// openStore opens the cookie store at path for recording.
+func openStore(path string) (cookies.KeyedStore, error) {
+	if filepath.Ext(path) == ".json" {
+		s, err := storagestate.Open(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return storagestate.New(path), nil
+		}
+		return s, err
+	}
+	s, err := openstore.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	ks, ok := s.(cookies.KeyedStore)
+	if !ok {
+		return nil, fmt.Errorf("store %T does not support recording", s)
+	}
+	return ks, nil
+}
@@ -35,11 +35,18 @@
 //
 // Each cookie has the following fields:
 //
-//	domain -- the host or domain for which the cookie is delivered
+//	domain -- the host or domain for which the cookie is delivered; a
+//	          domain cookie has a leading period, a host-only cookie not
 //	path   -- the path for which the cookie is delivered
 //	name   -- the name of the cookie
 //	value  -- the content of the cookie
 //
+// In addition, a field of the form "attr.<name>" refers to an attribute
+// specific to the format of the store, such as "attr.priority" for Chrome or
+// "attr.originAttributes" for Firefox. The attribute value is formatted as a
+// string for comparison. If the store does not define the attribute, its value
+// is empty.
+//
 // # Operators
 //
 // The operators are:
@@ -48,12 +55,22 @@
 //	?  -- test for key existence in the cookie
 //	~  -- regular expression search (RE2)
 //	@  -- domain-name string matching
+//	^  -- registrable domain (site) matching
 //
 // Any operator may be prefixed with '!' to negate the sense of the comparison.
 // If the key and operator are omitted, "domain" and "@" are assumed.  The "@"
 // operator does case-insensitive string comparison, but if the argument starts
 // with a period "." then it matches if the argument is a suffix of the value.
 //
+// The "^" operator matches if the value and the argument have the same
+// registrable domain (eTLD+1) according to the Public Suffix List, so that
+// "domain^bbc.co.uk" matches cookies from "bbc.co.uk" and "www.bbc.co.uk",
+// but not from "other.co.uk".
+//
+// A domain pattern such as ".co.uk" whose suffix is itself a public suffix
+// matches cookies from every site under that suffix. Such rules are accepted,
+// but a warning is recorded in the Warnings field of the Config.
+//
 // # Matching
 //
 // If a cookie is matched by any Keep ("!") rule, it is explicitly retained.
@@ -66,44 +83,30 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
 	"regexp"
 	"strings"
 
 	"github.com/creachadair/cookies"
-	"github.com/creachadair/cookies/bincookie"
-	"github.com/creachadair/cookies/chromedb"
-	"github.com/creachadair/cookies/firefox"
+	"github.com/creachadair/cookies/psl"
 )
 
-// OpenStore opens a cookie store for the specified path. The type of the
-// contents is inferred from the filename.
-func OpenStore(path string) (cookies.Store, error) {
-	if filepath.Ext(path) == ".binarycookies" {
-		return bincookie.Open(path)
-	}
-	p := strings.ToLower(path)
-	if strings.Contains(p, "google") && filepath.Base(p) == "cookies" {
-		return chromedb.Open(path, nil)
-	}
-	if strings.Contains(p, "firefox") && filepath.Base(p) == "cookies.sqlite" {
-		return firefox.Open(path, nil)
-	}
-	return nil, errors.New("unknown file type")
-}
-
 // Config represents the contents of a configuration file.
 type Config struct {
-	Files []string // any #= file lines
-	Rules []Rule
+	Files    []string // any #= file lines
+	Rules    []Rule
+	Warnings []string // diagnostics for suspicious rules
 }
 
 // Match returns a slice of rules matching the specified cookie, or nil if no
 // rules match.
-func (c *Config) Match(ck cookies.C) []Rule {
+func (c *Config) Match(ck cookies.C) []Rule { return c.MatchAttrs(ck, nil) }
+
+// MatchAttrs is as Match, but uses attrs as the format-specific attributes of
+// the cookie, as reported by [cookies.AttrEditor].
+func (c *Config) MatchAttrs(ck cookies.C, attrs map[string]any) []Rule {
 	var out []Rule
 	for _, r := range c.Rules {
-		if r.Match(ck) {
+		if r.MatchAttrs(ck, attrs) {
 			out = append(out, r)
 		}
 	}
@@ -119,9 +122,13 @@ type Rule struct {
 }
 
 // Match reports whether r matches the given cookie.
-func (r Rule) Match(ck cookies.C) bool {
+func (r Rule) Match(ck cookies.C) bool { return r.MatchAttrs(ck, nil) }
+
+// MatchAttrs reports whether r matches the given cookie with the given
+// format-specific attributes.
+func (r Rule) MatchAttrs(ck cookies.C, attrs map[string]any) bool {
 	for _, c := range r.Clauses {
-		if !c.Match(ck) {
+		if !c.MatchAttrs(ck, attrs) {
 			return false
 		}
 	}
@@ -130,8 +137,8 @@ func (r Rule) Match(ck cookies.C) bool {
 
 // A Clause is a single term of a rule.
 type Clause struct {
-	Field string // one of "domain", "path", "name", "value"
-	Op    string // one of "=", "?", "~", "@" or their negation
+	Field string // one of "domain", "path", "name", "value", or "attr.<name>"
+	Op    string // one of "=", "?", "~", "@", "^" or their negation
 	Arg   string // the RHS of the comparison
 
 	// If Op is "~" or "!~", Expr is the compiled regular expression
@@ -140,8 +147,12 @@ type Clause struct {
 }
 
 // Match reports whether c matches the corresponding field of ck.
-func (c Clause) Match(ck cookies.C) bool {
-	needle := fieldValue(c.Field, ck)
+func (c Clause) Match(ck cookies.C) bool { return c.MatchAttrs(ck, nil) }
+
+// MatchAttrs reports whether c matches the corresponding field of ck, with
+// the given format-specific attributes.
+func (c Clause) MatchAttrs(ck cookies.C, attrs map[string]any) bool {
+	needle := fieldValue(c.Field, ck, attrs)
 	op := strings.TrimPrefix(c.Op, "!")
 	want := op == c.Op
 	switch op {
@@ -153,31 +164,44 @@ func (c Clause) Match(ck cookies.C) bool {
 		return c.Expr.MatchString(needle) == want
 	case "@":
 		return domainMatch(needle, c.Arg) == want
+	case "^":
+		return (psl.Default().Site(needle) == psl.Default().Site(c.Arg)) == want
 	}
 	panic("unexpected operator")
 }
 
 func domainMatch(domain, pattern string) bool {
-	// compare strings case-insensitively
-	domain = strings.ToLower(domain)
-	pattern = strings.ToLower(pattern)
-
 	// pattern .foo.bar matches foo.bar and any.foo.bar
 	if strings.HasPrefix(pattern, ".") {
-		return domain == pattern[1:] || strings.HasSuffix(domain, pattern)
+		return cookies.DomainWithin(domain, pattern)
+	}
+	// pattern foo.bar matches just foo.bar, case-insensitively
+	return strings.EqualFold(domain, pattern)
+}
+
+// isSuffixPattern reports whether c is a domain match whose pattern matches
+// all the domains under a public suffix.
+func isSuffixPattern(c Clause) bool {
+	if c.Field != "domain" || c.Op != "@" || !strings.HasPrefix(c.Arg, ".") {
+		return false
 	}
-	// pattern foo.bar matches just foo.bar
-	return domain == pattern
+	return psl.Default().IsPublicSuffix(c.Arg)
 }
 
-func fieldValue(key string, ck cookies.C) string {
+func fieldValue(key string, ck cookies.C, attrs map[string]any) string {
+	if name, ok := strings.CutPrefix(key, "attr."); ok {
+		if v, ok := attrs[name]; ok {
+			return fmt.Sprint(v)
+		}
+		return ""
+	}
 	switch key {
 	case "name":
 		return ck.Name
 	case "value":
 		return ck.Value
 	case "domain":
-		return ck.Domain
+		return ck.HostKey()
 	case "path":
 		return ck.Path
 	default:
@@ -212,6 +236,12 @@ func Open(path string) (*Config, error) {
 			return nil, fmt.Errorf("line %d: %w", lnum, err)
 		}
 		cfg.Rules = append(cfg.Rules, rule)
+		for _, c := range rule.Clauses {
+			if isSuffixPattern(c) {
+				cfg.Warnings = append(cfg.Warnings, fmt.Sprintf(
+					"line %d: pattern %q matches every site under a public suffix", lnum, c.Arg))
+			}
+		}
 	}
 	return &cfg, buf.Err()
 }
@@ -238,14 +268,17 @@ func parseRule(line string) (Rule, error) {
 			out.Reason = c.Arg
 			continue
 		default:
-			return out, fmt.Errorf("unknown field %q", c.Field)
+			if !strings.HasPrefix(c.Field, "attr.") {
+				return out, fmt.Errorf("unknown field %q", c.Field)
+			}
+			// OK, format-specific attribute
 		}
 		out.Clauses = append(out.Clauses, c)
 	}
 	return out, nil
 }
 
-var ruleOp = regexp.MustCompile(`^(\w+)(!?[=~@?])`)
+var ruleOp = regexp.MustCompile(`^(\w+|attr\.\w+)(!?[=~@?^])`)
 
 func parseClause(arg string) (Clause, error) {
 	m := ruleOp.FindStringSubmatch(arg)
@@ -0,0 +1,56 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/creachadair/cookies"
+	"github.com/creachadair/cookies/cmd/washcookies/config"
+)
+
+func TestDomainField(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(`
++ domain=.example.com
++ domain~^\.
++ domain=host.example.org
+`), 0600); err != nil {
+		t.Fatalf("Write config: %v", err)
+	}
+	cfg, err := config.Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	// The domain field has a leading period for domain cookies only.
+	tests := []struct {
+		ck   cookies.C
+		want int // number of matching rules
+	}{
+		{cookies.C{Domain: "example.com"}, 2},
+		{cookies.C{Domain: "example.com", Flags: cookies.Flags{HostOnly: true}}, 0},
+		{cookies.C{Domain: "host.example.org"}, 1},
+		{cookies.C{Domain: "host.example.org", Flags: cookies.Flags{HostOnly: true}}, 1},
+	}
+	for _, tc := range tests {
+		if got := cfg.Match(tc.ck); len(got) != tc.want {
+			t.Errorf("Match %q (host-only %v): got %d rules, want %d",
+				tc.ck.Domain, tc.ck.Flags.HostOnly, len(got), tc.want)
+		}
+	}
+}
@@ -42,16 +42,20 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"log"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"text/tabwriter"
 
 	"github.com/creachadair/cookies"
 	"github.com/creachadair/cookies/cmd/washcookies/config"
+	"github.com/creachadair/cookies/internal/openstore"
 
 	// Import SQLite3 driver for database/sql.
 	_ "modernc.org/sqlite"
@@ -94,6 +98,9 @@ func main() {
 	if err != nil {
 		log.Fatalf("Invalid config: %v", err)
 	}
+	for _, w := range cfg.Warnings {
+		fmt.Fprintf(os.Stderr, "⚠️  %s\n", w)
+	}
 
 	// If the user specified non-flag arguments, use them instead of the file
 	// list from the configuration file.
@@ -110,10 +117,13 @@ func main() {
 
 	for _, path := range cfg.Files {
 		path = os.ExpandEnv(path)
-		s, err := config.OpenStore(path)
+		s, err := openstore.Open(path)
 		if os.IsNotExist(err) {
 			log.Printf("Skipping %q, file not found", path)
 			continue
+		} else if errors.Is(err, cookies.ErrLocked) {
+			log.Printf("Skipping %q, browser is running (%v)", path, err)
+			continue
 		} else if err != nil {
 			log.Fatalf("Opening %q: %v", path, err)
 		}
@@ -122,13 +132,17 @@ func main() {
 		var nKept, nDiscarded int
 		if err := s.Scan(func(e cookies.Editor) (cookies.Action, error) {
 			ck := e.Get()
+			var attrs map[string]any
+			if ae, ok := e.(cookies.AttrEditor); ok {
+				attrs = ae.Attrs()
+			}
 			var allowReason, denyReason string
 			var allow, deny bool
-			for _, rule := range cfg.Match(ck) {
+			for _, rule := range cfg.MatchAttrs(ck, attrs) {
 				switch rule.Tag {
 				case "!":
 					nKept++
-					vlog(message("✨", ck, rule.Reason))
+					vlog(message("✨", ck, attrs, rule.Reason))
 					return cookies.Keep, nil
 				case "-":
 					deny = true
@@ -140,14 +154,14 @@ func main() {
 			}
 			if deny || !allow {
 				nDiscarded++
-				fmt.Fprint(tw, message("🚫", ck, denyReason))
+				fmt.Fprint(tw, message("🚫", ck, attrs, denyReason))
 				if *doDryRun {
 					return cookies.Keep, nil
 				}
 				return cookies.Discard, nil
 			}
 			nKept++
-			vlog(message("🆗", ck, allowReason))
+			vlog(message("🆗", ck, attrs, allowReason))
 			return cookies.Keep, nil
 		}); err != nil {
 			log.Fatalf("Scanning %q: %v", path, err)
@@ -166,7 +180,19 @@ func vlog(msg string) {
 	}
 }
 
-func message(emo string, ck cookies.C, reason string) string {
-	args := []string{" " + emo, ck.Domain, ck.Name, reason}
+func message(emo string, ck cookies.C, attrs map[string]any, reason string) string {
+	args := []string{" " + emo, ck.HostKey(), ck.Name, formatAttrs(attrs), reason}
 	return strings.Join(args, "\t") + "\n"
 }
+
+// formatAttrs renders non-empty format-specific attributes as name=value
+// pairs, in order by name.
+func formatAttrs(attrs map[string]any) string {
+	var out []string
+	for _, name := range slices.Sorted(maps.Keys(attrs)) {
+		if v := fmt.Sprint(attrs[name]); v != "" {
+			out = append(out, name+"="+v)
+		}
+	}
+	return strings.Join(out, " ")
+}
@@ -25,11 +25,52 @@
 // providing a Scan method that can be used to visit each cookie, examine and
 // possibly modify its contents, and decide whether to retain the cookie as it
 // was (Keep), update it (Update), or discard it (Discard).
+//
+// To visit only the cookies matching a [Query], use [ScanWhere]. Stores that
+// can select cookies more efficiently, such as databases, implement the
+// [FilteredStore] interface.
+//
+// To read cookies without modifying them, range over [All]:
+//
+//	for c, err := range cookies.All(s) {
+//		// ...
+//	}
+//
+// To find the cookies a browser would send in a request for a URL, use
+// [ForURL], and use [CookieHeader] to format them for the request.  To
+// convert between cookies and the syntax of the Set-Cookie response header,
+// use [ParseSetCookie] and [FormatSetCookie].
+//
+// To cancel a scan or give it a deadline, use [ScanContext].
+//
+// To look up, add, or remove a single cookie without scanning, use a store
+// that implements the [KeyedStore] interface.
 package cookies
 
-import "time"
+import (
+	"context"
+	"errors"
+	"iter"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is reported by the Get method of a [KeyedStore] when no cookie
+// has the requested key.
+var ErrNotFound = errors.New("cookie not found")
+
+// ErrLocked is reported when a store cannot be opened for writing because it
+// is in use by another program, such as a running browser. Implementations
+// should wrap this error so that callers can detect it with [errors.Is].
+var ErrLocked = errors.New("store is in use by another program")
 
 // C is a format-independent representation of a browser cookie.
+//
+// The Domain of a cookie is a host or domain name without a leading period.
+// Whether the cookie applies only to that host, or to the whole domain, is
+// recorded by the HostOnly flag. Browser stores conventionally mark domain
+// cookies with a leading period instead; use [C.HostKey] and [ParseHostKey]
+// to convert between the two forms.
 type C struct {
 	Name   string
 	Value  string
@@ -42,6 +83,36 @@ type C struct {
 	SameSite SameSite
 }
 
+// Key returns the key that identifies c within a store.
+func (c C) Key() Key { return Key{Domain: c.HostKey(), Name: c.Name, Path: c.Path} }
+
+// HostKey returns the domain of c in the form conventionally stored by
+// browsers: With a leading period for a domain cookie, and without one for a
+// host-only cookie.
+func (c C) HostKey() string {
+	d := strings.TrimPrefix(c.Domain, ".")
+	if c.Flags.HostOnly {
+		return d
+	}
+	return "." + d
+}
+
+// ParseHostKey parses a domain in the form returned by [C.HostKey], and
+// returns the domain name without a leading period, and whether the cookie is
+// host-only.
+func ParseHostKey(s string) (domain string, hostOnly bool) {
+	domain, ok := strings.CutPrefix(s, ".")
+	return domain, !ok
+}
+
+// A Key identifies a cookie within a store. A store holds at most one cookie
+// for each distinct key.
+type Key struct {
+	Domain string // as returned by C.HostKey
+	Name   string
+	Path   string
+}
+
 // SameSite describes a first-party cookie policy.
 type SameSite int
 
@@ -66,6 +137,11 @@ func (s SameSite) String() string {
 type Flags struct {
 	Secure   bool // only send this cookie on an encrypted connection
 	HTTPOnly bool // do not expose this cookie to scripts
+	HostOnly bool // send this cookie only to the host named by its domain
+
+	// Partitioned marks a cookie kept in storage partitioned by top-level
+	// site (CHIPS). Stores that do not record partitioning ignore it.
+	Partitioned bool
 }
 
 // An Editor maps between format-specific representation of a cookie and the
@@ -74,11 +150,28 @@ type Editor interface {
 	// Get returns a format-independent representation of the receiver.
 	Get() C
 
-	// Set updates the contents of the receiver to match c.
-	// It reports an error if c cannot be represented in the format.
+	// Set updates the contents of the receiver to match c.  It reports an
+	// error if c is not valid (see [Validate]), or cannot be represented in
+	// the format.
 	Set(c C) error
 }
 
+// An AttrEditor is an Editor that also exposes attributes specific to the
+// format of its store, which are not captured by the fields of [C].
+type AttrEditor interface {
+	Editor
+
+	// Attrs returns a map of the names and current values of the
+	// format-specific attributes of the receiver. Modifying the map does not
+	// change the receiver.
+	Attrs() map[string]any
+
+	// SetAttr sets the value of the named attribute. It reports an error if
+	// the name is not known, or if value does not have the type reported for
+	// that name by Attrs.
+	SetAttr(name string, value any) error
+}
+
 // An Action specifies the disposition of a cookie processed by the callback to
 // the Scan method of a [Store].
 type Action int
@@ -124,3 +217,81 @@ type Store interface {
 	// Commit commits any pending modifications to persistent storage.
 	Commit() error
 }
+
+// A ContextStore is a Store whose scans can be cancelled through a context.
+type ContextStore interface {
+	Store
+
+	// ScanContext behaves as Scan, but stops early if ctx ends before the scan
+	// is complete, and reports the error from ctx.
+	ScanContext(ctx context.Context, f ScanFunc) error
+}
+
+// ScanContext calls f for each cookie in s, with the semantics of the Scan
+// method of a [Store], stopping early if ctx ends. If s implements
+// [ContextStore], its ScanContext method is used; otherwise ScanContext calls
+// s.Scan and checks ctx before each call to f.
+func ScanContext(ctx context.Context, s Store, f ScanFunc) error {
+	if cs, ok := s.(ContextStore); ok {
+		return cs.ScanContext(ctx, f)
+	}
+	return s.Scan(func(e Editor) (Action, error) {
+		if err := ctx.Err(); err != nil {
+			return Keep, err
+		}
+		return f(e)
+	})
+}
+
+// An IterStore is a Store that can iterate over its cookies for reading only,
+// without the overhead of preparing to apply changes.
+type IterStore interface {
+	Store
+
+	// All returns an iterator over the cookies in the store. If an error
+	// occurs, it is yielded with a zero C and iteration stops. The store must
+	// not be modified while an iteration is in progress.
+	All() iter.Seq2[C, error]
+}
+
+// All returns an iterator over the cookies in s, for reading only.  If s
+// implements [IterStore], its All method is used; otherwise All calls s.Scan
+// and keeps every cookie. If an error occurs, it is yielded with a zero C and
+// iteration stops.
+func All(s Store) iter.Seq2[C, error] {
+	if is, ok := s.(IterStore); ok {
+		return is.All()
+	}
+	return func(yield func(C, error) bool) {
+		err := s.Scan(func(e Editor) (Action, error) {
+			if !yield(e.Get(), nil) {
+				return Keep, errStopIter
+			}
+			return Keep, nil
+		})
+		if err != nil && err != errStopIter {
+			yield(C{}, err)
+		}
+	}
+}
+
+// errStopIter is used by All to end a scan when the caller stops iterating.
+var errStopIter = errors.New("stop iteration")
+
+// A KeyedStore is a Store that supports direct access to individual cookies
+// by their key, without scanning the whole store. As with Scan, changes made
+// by Put and Delete are persisted by the Commit method of the store.
+type KeyedStore interface {
+	Store
+
+	// Get returns the cookie with the given key. If there is no such cookie,
+	// Get reports ErrNotFound.
+	Get(key Key) (C, error)
+
+	// Put adds c to the store, replacing any existing cookie with the same key.
+	Put(c C) error
+
+	// Delete removes the cookie with the given key, if it exists. It is not an
+	// error to delete a key that is not present.
+	Delete(key Key) error
+}
@@ -0,0 +1,122 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cookies_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/creachadair/cookies"
+)
+
+// sliceStore is a minimal cookies.Store backed by a slice.
+type sliceStore []cookies.C
+
+type sliceEditor struct{ c *cookies.C }
+
+func (e sliceEditor) Get() cookies.C        { return *e.c }
+func (e sliceEditor) Set(c cookies.C) error { *e.c = c; return nil }
+
+func (s *sliceStore) Scan(f cookies.ScanFunc) error {
+	var out []cookies.C
+	for _, c := range *s {
+		tmp := c
+		act, err := f(sliceEditor{&tmp})
+		if err != nil {
+			return err
+		}
+		switch act {
+		case cookies.Keep:
+			out = append(out, c)
+		case cookies.Update:
+			out = append(out, tmp)
+		}
+	}
+	*s = out
+	return nil
+}
+
+func (s *sliceStore) Commit() error { return nil }
+
+func TestScanContext(t *testing.T) {
+	s := &sliceStore{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	var n int
+	err := cookies.ScanContext(ctx, s, func(e cookies.Editor) (cookies.Action, error) {
+		if n++; n == 2 {
+			cancel()
+		}
+		return cookies.Keep, nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("ScanContext: got error %v, want %v", err, context.Canceled)
+	}
+	if n != 2 {
+		t.Errorf("ScanContext: visited %d cookies, want 2", n)
+	}
+}
+
+func TestAll(t *testing.T) {
+	s := &sliceStore{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+
+	var got []string
+	for c, err := range cookies.All(s) {
+		if err != nil {
+			t.Fatalf("All: unexpected error: %v", err)
+		}
+		got = append(got, c.Name)
+		if c.Name == "b" {
+			break
+		}
+	}
+	if len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("All: got %q, want [a b]", got)
+	}
+	if len(*s) != 3 {
+		t.Errorf("After All: store has %d cookies, want 3", len(*s))
+	}
+}
+
+func TestHostKey(t *testing.T) {
+	tests := []struct {
+		key      string
+		domain   string
+		hostOnly bool
+	}{
+		{".example.com", "example.com", false},
+		{"www.example.com", "www.example.com", true},
+		{"127.0.0.1", "127.0.0.1", true},
+	}
+	for _, tc := range tests {
+		domain, hostOnly := cookies.ParseHostKey(tc.key)
+		if domain != tc.domain || hostOnly != tc.hostOnly {
+			t.Errorf("ParseHostKey(%q): got (%q, %v), want (%q, %v)",
+				tc.key, domain, hostOnly, tc.domain, tc.hostOnly)
+		}
+		c := cookies.C{Domain: domain, Flags: cookies.Flags{HostOnly: hostOnly}}
+		if got := c.HostKey(); got != tc.key {
+			t.Errorf("HostKey(%q, %v): got %q, want %q", domain, hostOnly, got, tc.key)
+		}
+	}
+
+	// A leading period is not doubled for a domain cookie.
+	c := cookies.C{Domain: ".example.com"}
+	if got, want := c.HostKey(), ".example.com"; got != want {
+		t.Errorf("HostKey: got %q, want %q", got, want)
+	}
+}
@@ -16,93 +16,259 @@
 package firefox
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"iter"
+	"strings"
 	"time"
 
 	"github.com/creachadair/cookies"
+	"github.com/creachadair/cookies/internal/dbfile"
 )
 
 // Open opens the Firefox cookie database at the specified path.
 // If opts == nil, default options are used.
 func Open(path string, opts *Options) (*Store, error) {
-	db, err := sql.Open(opts.driver(), path)
+	if !opts.readOnly() && !opts.snapshot() && !opts.ignoreLock() {
+		if err := checkLock(path); err != nil {
+			return nil, err
+		}
+	}
+	var snap *dbfile.Snapshot
+	if opts.snapshot() {
+		var err error
+		snap, err = dbfile.NewSnapshot(path)
+		if err != nil {
+			return nil, fmt.Errorf("snapshot: %w", err)
+		}
+		path = snap.Path
+	}
+	db, err := sql.Open(opts.driver(), dbfile.URI(path, opts.readOnly() && snap == nil))
+	if err != nil {
+		if snap != nil {
+			snap.Close()
+		}
+		return nil, err
+	}
+	s, err := OpenDB(db, opts)
 	if err != nil {
+		db.Close()
+		if snap != nil {
+			snap.Close()
+		}
 		return nil, err
 	}
-	return &Store{db: db}, nil
+	s.ownDB = true
+	s.readOnly = s.readOnly || snap != nil
+	s.snap = snap
+	return s, nil
+}
+
+// OpenDB returns a Store that reads and modifies the Firefox cookie database
+// accessed through db. The caller remains responsible for closing db; the
+// Close method of the resulting store does not close it. OpenDB reports an
+// error if db has no moz_cookies table.
+//
+// The Driver, Snapshot, and IgnoreLock options do not apply to OpenDB.
+func OpenDB(db *sql.DB, opts *Options) (*Store, error) {
+	if err := checkTable(db); err != nil {
+		return nil, err
+	}
+	return &Store{db: db, readOnly: opts.readOnly()}, nil
+}
+
+// checkTable reports an error if db does not have a cookies table.
+func checkTable(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	_, err = dbfile.Columns(tx, "moz_cookies")
+	return err
 }
 
 // Options are optional settings for a Store.
 // A nil *Options is ready for use with default settings.
-type Options struct{}
+type Options struct {
+	// If true, open the database read-only and treat it as immutable, so that
+	// no locks are taken. Firefox keeps recent changes in cookies.sqlite-wal,
+	// which an immutable connection does not read, and checkpoints it into
+	// the database while running, so this is safe only when Firefox is not
+	// running. To read the cookies of a running Firefox, use Snapshot.
+	ReadOnly bool
+
+	// If true, copy cookies.sqlite and cookies.sqlite-wal into a temporary
+	// directory and open the copy, so that changes Firefox has not yet
+	// checkpointed are included and its exclusive lock is not contended. The
+	// resulting store is read-only, and must be closed to remove the copy.
+	Snapshot bool
+
+	// If true, open the database for writing even if the browser appears to
+	// be running. By default, Open reports an error wrapping cookies.ErrLocked
+	// in that case, since the browser may overwrite or corrupt the changes.
+	IgnoreLock bool
+
+	// The name of the database/sql driver to use to open the database.  The
+	// driver must be registered by the caller, typically with a blank import.
+	// If empty, "sqlite" is used, as registered by modernc.org/sqlite.
+	Driver string
+}
 
-func (*Options) driver() string { return "sqlite" }
+func (o *Options) driver() string {
+	if o == nil || o.Driver == "" {
+		return "sqlite"
+	}
+	return o.Driver
+}
+
+func (o *Options) readOnly() bool { return o != nil && o.ReadOnly }
+func (o *Options) snapshot() bool { return o != nil && o.Snapshot }
+
+func (o *Options) ignoreLock() bool { return o != nil && o.IgnoreLock }
 
 // A Store connects to a collection of cookies storeed in an SQLite database
 // using the Firefox cookie schema.
 type Store struct {
-	db *sql.DB
+	db       *sql.DB
+	readOnly bool
+	ownDB    bool             // whether Close should close db
+	snap     *dbfile.Snapshot // if opened from a snapshot
 }
 
 // Scan implements part of the [cookies.Store] interface.
 func (s *Store) Scan(f cookies.ScanFunc) error {
-	cs, err := s.readCookies()
-	if err != nil {
-		return err
-	}
+	return s.scanWhere(context.Background(), cookies.Query{}, f)
+}
 
-	tx, err := s.db.Begin()
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
+// ScanContext implements the [cookies.ContextStore] interface.  If ctx ends
+// before the scan is complete, the scan stops, no changes are applied, and
+// ScanContext reports the error from ctx.
+func (s *Store) ScanContext(ctx context.Context, f cookies.ScanFunc) error {
+	return s.scanWhere(ctx, cookies.Query{}, f)
+}
 
-	for _, c := range cs {
-		act, err := f(c)
-		if err != nil {
-			return err
-		}
-		switch act {
-		case cookies.Keep:
-			continue
-
-		case cookies.Update:
-			if err := s.writeCookie(tx, c); err != nil {
-				return err
-			}
-
-		case cookies.Discard:
-			if err := s.dropCookie(tx, c); err != nil {
-				return err
-			}
-
-		default:
-			return fmt.Errorf("unknown action %v", act)
-		}
+// All implements the [cookies.IterStore] interface.  Cookies are read in batches
+// of increasing ID within a read-only transaction.
+func (s *Store) All() iter.Seq2[cookies.C, error] { return s.table().All() }
+
+// ScanWhere implements the [cookies.FilteredStore] interface.  The conditions
+// of q are evaluated by the database, so only matching cookies are read.
+//
+// Rows are read a batch at a time, so a profile with many cookies is not held
+// in memory at once. All changes are applied in a single transaction, which
+// is committed only if the scan completes without error.
+func (s *Store) ScanWhere(q cookies.Query, f cookies.ScanFunc) error {
+	return s.scanWhere(context.Background(), q, f)
+}
+
+func (s *Store) scanWhere(ctx context.Context, q cookies.Query, f cookies.ScanFunc) error {
+	return s.table().Scan(ctx, q, f)
+}
+
+// table returns a scanner for the moz_cookies table of s.
+func (s *Store) table() *dbfile.Table[*Cookie] {
+	return &dbfile.Table[*Cookie]{
+		DB:       s.db,
+		ReadOnly: s.readOnly,
+		Filter: dbfile.Filter{
+			Host:         "host",
+			Name:         "name",
+			Expires:      "expiry",
+			ExpiresValue: dbfile.CeilUnix, // expiry is in whole seconds
+		},
+		Read:  s.readCookies,
+		ID:    func(c *Cookie) int64 { return c.id },
+		Write: s.writeCookie,
+		Drop:  s.dropCookie,
 	}
-	return tx.Commit()
 }
 
+// ReadOnly reports whether s was opened read-only, either by the ReadOnly
+// option or from a snapshot. Changes to a read-only store report an error.
+func (s *Store) ReadOnly() bool { return s.readOnly }
+
 // Commit implements part of the [cookies.Store] interface.
 func (s *Store) Commit() error { return nil }
 
+// Close closes the database, and removes its snapshot if it has one.  If s was
+// created by OpenDB, Close does not close the database.
+func (s *Store) Close() error {
+	var err error
+	if s.ownDB {
+		err = s.db.Close()
+	}
+	if s.snap != nil {
+		err = errors.Join(err, s.snap.Close())
+	}
+	return err
+}
+
+// A Cookie represents a single cookie from a Firefox database.
+//
+// A Cookie also implements the [cookies.AttrEditor] interface, with the
+// following Firefox-specific attributes:
+//
+//	originAttributes -- string, the container and isolation key of the cookie
 type Cookie struct {
 	cookies.C
 
-	id int64
+	originAttrs string
+	id          int64
 }
 
 // Get implements part of the [cookies.Editor] interface.
 func (c *Cookie) Get() cookies.C { return c.C }
 
 // Set implements part of the [cookies.Editor] interface.
-func (c *Cookie) Set(o cookies.C) error { c.C = o; return nil }
+// It reports an error if o is not valid according to [cookies.Validate], or
+// is a session cookie, which the database cannot hold.
+func (c *Cookie) Set(o cookies.C) error {
+	if err := checkCookie(o); err != nil {
+		return err
+	}
+	c.C = o
+	return nil
+}
+
+// checkCookie reports whether c can be stored in the database. Firefox keeps
+// session cookies in memory, and the expiry column has no encoding for them.
+func checkCookie(c cookies.C) error {
+	if err := cookies.Validate(c); err != nil {
+		return err
+	} else if c.Expires.IsZero() {
+		return fmt.Errorf("%w: session cookie %q cannot be stored", cookies.ErrInvalid, c.Name)
+	}
+	return nil
+}
+
+// Attrs implements part of the [cookies.AttrEditor] interface.
+func (c *Cookie) Attrs() map[string]any { return map[string]any{"originAttributes": c.originAttrs} }
 
-func (s *Store) readCookies() ([]*Cookie, error) {
-	rows, err := s.db.Query(`SELECT ` +
-		`id, name, value, host, path, expiry, creationTime, isSecure, isHttpOnly, sameSite ` +
-		`FROM moz_cookies`)
+// SetAttr implements part of the [cookies.AttrEditor] interface.
+func (c *Cookie) SetAttr(name string, value any) error {
+	switch name {
+	case "originAttributes":
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("attribute %q: got %T, want string", name, value)
+		}
+		c.originAttrs = v
+		return nil
+	default:
+		return fmt.Errorf("unknown attribute %q", name)
+	}
+}
+
+// readCookies reads up to limit cookies satisfying the SQL conditions in conds
+// whose IDs are greater than after, in order of increasing ID.
+func (s *Store) readCookies(ctx context.Context, tx *sql.Tx, after int64, limit int, conds []string, args []any) ([]*Cookie, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT `+
+		`id, name, value, host, path, expiry, creationTime, isSecure, isHttpOnly, sameSite, originAttributes `+
+		`FROM moz_cookies WHERE `+strings.Join(append([]string{"id > ?"}, conds...), " AND ")+
+		` ORDER BY id LIMIT ?`, append(append([]any{after}, args...), limit)...)
 	if err != nil {
 		return nil, err
 	}
@@ -112,48 +278,49 @@ func (s *Store) readCookies() ([]*Cookie, error) {
 	for rows.Next() {
 		var rowID, expiry, creationTime, sameSite int64
 		var isSecure, isHTTPOnly bool
-		var name, value, host, path string
+		var name, value, host, path, originAttrs string
 
 		if err := rows.Scan(&rowID, &name, &value, &host, &path, &expiry, &creationTime,
-			&isSecure, &isHTTPOnly, &sameSite); err != nil {
+			&isSecure, &isHTTPOnly, &sameSite, &originAttrs); err != nil {
 			return nil, err
 		}
 
+		domain, hostOnly := cookies.ParseHostKey(host)
 		cs = append(cs, &Cookie{
 			C: cookies.C{
 				Name:    name,
 				Value:   value,
-				Domain:  host,
+				Domain:  domain,
 				Path:    path,
 				Expires: time.Unix(expiry, 0).UTC(),
 				Created: time.UnixMicro(creationTime).UTC(),
 				Flags: cookies.Flags{
 					Secure:   isSecure,
 					HTTPOnly: isHTTPOnly,
+					HostOnly: hostOnly,
 				},
 				SameSite: decodeSitePolicy(sameSite),
 			},
-			id: rowID,
+			originAttrs: originAttrs,
+			id:          rowID,
 		})
 	}
-	return cs, nil
+	return cs, rows.Err()
 }
 
-func (s *Store) dropCookie(tx *sql.Tx, c *Cookie) error {
-	_, err := tx.Exec(`DELETE FROM moz_cookies WHERE id = ?`, c.id)
-	return err
+func (s *Store) dropCookie(w *dbfile.Writer, c *Cookie) error {
+	return w.Exec(`DELETE FROM moz_cookies WHERE id = ?`, c.id)
 }
 
-func (s *Store) writeCookie(tx *sql.Tx, c *Cookie) error {
-	_, err := tx.Exec(`UPDATE moz_cookies SET `+
+func (s *Store) writeCookie(w *dbfile.Writer, c *Cookie) error {
+	return w.Exec(`UPDATE moz_cookies SET `+
 		`name = ?, value = ?, host = ?, path = ?, expiry = ?, creationTime = ?, `+
-		`isSecure = ?, isHttpOnly = ?, sameSite = ? `+
+		`isSecure = ?, isHttpOnly = ?, sameSite = ?, originAttributes = ? `+
 		`WHERE id = ?`,
-		c.Name, c.Value, c.Domain, c.Path, c.Expires.Unix(), c.Created.UnixMicro(),
+		c.Name, c.Value, c.HostKey(), c.Path, c.Expires.Unix(), c.Created.UnixMicro(),
 		boolToInt(c.Flags.Secure), boolToInt(c.Flags.HTTPOnly), encodeSitePolicy(c.SameSite),
-		c.id,
+		c.originAttrs, c.id,
 	)
-	return err
 }
 
 func boolToInt(ok bool) int {
@@ -15,11 +15,21 @@
 package firefox_test
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/creachadair/cookies"
 	"github.com/creachadair/cookies/firefox"
+	"github.com/creachadair/cookies/internal/dbfile/dbtest"
+	"github.com/google/go-cmp/cmp"
 
 	_ "modernc.org/sqlite"
 )
@@ -59,3 +69,497 @@ func TestManual(t *testing.T) {
 
 	t.Logf("Found %d cookies", numCookies)
 }
+
+// createTable is the Firefox cookie schema.
+const createTable = `CREATE TABLE moz_cookies (
+  id INTEGER PRIMARY KEY, originAttributes TEXT NOT NULL DEFAULT '',
+  name TEXT, value TEXT, host TEXT, path TEXT, expiry INTEGER,
+  lastAccessed INTEGER, creationTime INTEGER, isSecure INTEGER, isHttpOnly INTEGER,
+  inBrowserElement INTEGER DEFAULT 0, sameSite INTEGER DEFAULT 0,
+  rawSameSite INTEGER DEFAULT 0, schemeMap INTEGER DEFAULT 0)`
+
+// newTestDB creates a Firefox cookie database in a temporary directory,
+// populated with the given cookies, and returns its path.
+func newTestDB(t testing.TB, cs ...cookies.C) string {
+	t.Helper()
+	path := dbtest.NewDB(t, "cookies.sqlite", createTable)
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("Open test database: %v", err)
+	}
+	defer db.Close()
+	insertCookies(t, db, cs...)
+	return path
+}
+
+// initTestDB creates the cookie schema in db and populates it with cs.
+func initTestDB(t testing.TB, db *sql.DB, cs ...cookies.C) {
+	t.Helper()
+	if _, err := db.Exec(createTable); err != nil {
+		t.Fatalf("Create table: %v", err)
+	}
+	insertCookies(t, db, cs...)
+}
+
+// insertCookies adds cs to the moz_cookies table of db.
+func insertCookies(t testing.TB, db *sql.DB, cs ...cookies.C) {
+	t.Helper()
+	for _, c := range cs {
+		if _, err := db.Exec(`INSERT INTO moz_cookies `+
+			`(name, value, host, path, expiry, lastAccessed, creationTime, isSecure, isHttpOnly, sameSite) `+
+			`VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			c.Name, c.Value, c.HostKey(), c.Path, c.Expires.Unix(), c.Created.UnixMicro(),
+			c.Created.UnixMicro(), c.Flags.Secure, c.Flags.HTTPOnly, sameSiteValue[c.SameSite]); err != nil {
+			t.Fatalf("Insert cookie: %v", err)
+		}
+	}
+}
+
+// sameSiteValue maps generic SameSite policies to Firefox values.
+var sameSiteValue = map[cookies.SameSite]int{cookies.None: 0, cookies.Lax: 1, cookies.Strict: 2}
+
+func TestReadOnly(t *testing.T) {
+	path := newTestDB(t,
+		cookies.C{Name: "a", Domain: ".example.com", Path: "/"},
+		cookies.C{Name: "b", Domain: "example.com", Path: "/"},
+	)
+	discard := func(cookies.Editor) (cookies.Action, error) { return cookies.Discard, nil }
+
+	for _, opts := range []*firefox.Options{{ReadOnly: true}, {Snapshot: true}} {
+		s, err := firefox.Open(path, opts)
+		if err != nil {
+			t.Fatalf("Open %+v: %v", opts, err)
+		}
+		if diff := cmp.Diff([]string{"a", "b"}, dbtest.ScanNames(t, s)); diff != "" {
+			t.Errorf("Names %+v: (-want, +got)\n%s", opts, diff)
+		}
+		if err := s.Scan(discard); err == nil {
+			t.Errorf("Scan %+v with Discard: got nil, want error", opts)
+		}
+		if err := s.Close(); err != nil {
+			t.Errorf("Close %+v: %v", opts, err)
+		}
+	}
+
+	// The original database should be unaffected.
+	s, err := firefox.Open(path, nil)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+	if diff := cmp.Diff([]string{"a", "b"}, dbtest.ScanNames(t, s)); diff != "" {
+		t.Errorf("Names: (-want, +got)\n%s", diff)
+	}
+}
+
+func TestLock(t *testing.T) {
+	path := newTestDB(t, cookies.C{Name: "a", Domain: "example.com", Path: "/"})
+	lock := filepath.Join(filepath.Dir(path), "lock")
+	setLock := func(target string) {
+		t.Helper()
+		os.Remove(lock)
+		if err := os.Symlink(target, lock); err != nil {
+			t.Fatalf("Create lock: %v", err)
+		}
+	}
+
+	// A lock held by a live process, or by a process on another host, blocks.
+	for _, target := range []string{
+		fmt.Sprintf("127.0.0.1:+%d", os.Getpid()),
+		"192.0.2.1:+12345",
+	} {
+		setLock(target)
+		if s, err := firefox.Open(path, nil); !errors.Is(err, cookies.ErrLocked) {
+			t.Errorf("Open with lock %q: got (%v, %v), want %v", target, s, err, cookies.ErrLocked)
+		}
+	}
+	for _, opts := range []*firefox.Options{{ReadOnly: true}, {IgnoreLock: true}} {
+		s, err := firefox.Open(path, opts)
+		if err != nil {
+			t.Errorf("Open %+v: unexpected error: %v", opts, err)
+		} else {
+			s.Close()
+		}
+	}
+
+	// A lock left by a process that has exited does not block.
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Run subprocess: %v", err)
+	}
+	setLock(fmt.Sprintf("127.0.0.1:+%d", cmd.Process.Pid))
+	if s, err := firefox.Open(path, nil); err != nil {
+		t.Errorf("Open with stale lock: unexpected error: %v", err)
+	} else {
+		s.Close()
+	}
+
+	os.Remove(lock)
+	s, err := firefox.Open(path, nil)
+	if err != nil {
+		t.Fatalf("Open without lock: unexpected error: %v", err)
+	}
+	s.Close()
+}
+
+func TestOpenDB(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("Open database: %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1) // each connection has its own in-memory database
+	initTestDB(t, db, cookies.C{Name: "a", Domain: "example.com", Path: "/"})
+
+	s, err := firefox.OpenDB(db, nil)
+	if err != nil {
+		t.Fatalf("OpenDB: %v", err)
+	}
+	if diff := cmp.Diff([]string{"a"}, dbtest.ScanNames(t, s)); diff != "" {
+		t.Errorf("Names: (-want, +got)\n%s", diff)
+	}
+	if err := s.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+
+	// Closing the store should not close the caller's database.
+	if err := db.Ping(); err != nil {
+		t.Errorf("Ping after Close: %v", err)
+	}
+
+	// An unregistered driver should be reported.
+	path := filepath.Join(t.TempDir(), "cookies.sqlite")
+	if s, err := firefox.Open(path, &firefox.Options{Driver: "nonesuch"}); err == nil {
+		t.Errorf("Open with bad driver: got %v, want error", s)
+	}
+
+	// A database without a cookies table should be reported.
+	empty, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("Open database: %v", err)
+	}
+	defer empty.Close()
+	if s, err := firefox.OpenDB(empty, nil); err == nil {
+		t.Errorf("OpenDB without a table: got %v, want error", s)
+	}
+}
+
+func TestScanWhere(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	all := []cookies.C{
+		{Name: "a", Domain: ".example.com", Path: "/", Expires: now.Add(time.Hour)},
+		{Name: "b", Domain: "www.example.com", Path: "/", Expires: now.Add(-time.Hour)},
+		{Name: "a", Domain: "badexample.com", Path: "/", Expires: now.Add(time.Hour)},
+		{Name: "c", Domain: "ex_mple.com", Path: "/", Expires: now.Add(time.Hour)},
+	}
+	s, err := firefox.Open(newTestDB(t, all...), nil)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+
+	for _, q := range []cookies.Query{
+		{},
+		{Domain: "example.com"},
+		{Domain: "www.example.com"},
+		{Domain: "ex_mple.com"},
+		{Name: "a"},
+		{Domain: "example.com", Name: "a"},
+		{ExpiresBefore: now},
+		{ExpiresAfter: now},
+	} {
+		var want, got []string
+		for _, c := range all {
+			if q.Match(c) {
+				want = append(want, c.HostKey()+":"+c.Name)
+			}
+		}
+		if err := s.ScanWhere(q, func(e cookies.Editor) (cookies.Action, error) {
+			c := e.Get()
+			got = append(got, c.HostKey()+":"+c.Name)
+			return cookies.Keep, nil
+		}); err != nil {
+			t.Fatalf("ScanWhere %+v: %v", q, err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("ScanWhere %+v: (-want, +got)\n%s", q, diff)
+		}
+	}
+}
+
+// newLargeDB creates a Firefox cookie database with n generated cookies, and
+// returns its path.
+func newLargeDB(t testing.TB, n int) string {
+	t.Helper()
+	path := newTestDB(t)
+	now := time.Now()
+	dbtest.Fill(t, path, n, `INSERT INTO moz_cookies `+
+		`(name, value, host, path, expiry, lastAccessed, creationTime, isSecure, isHttpOnly) `+
+		`VALUES (?, ?, ?, '/', ?, ?, ?, 0, 0)`,
+		func(i int) []any {
+			return []any{fmt.Sprintf("name%d", i), fmt.Sprintf("value%d", i),
+				fmt.Sprintf(".host%d.example.com", i%1000), now.Add(time.Hour).Unix(),
+				now.UnixMicro(), now.UnixMicro()}
+		})
+	return path
+}
+
+func BenchmarkScan(b *testing.B) {
+	dbtest.BenchmarkScan(b, newLargeDB(b, 100_000), "moz_cookies", func(path string) (dbtest.Store, error) {
+		return firefox.Open(path, nil)
+	})
+}
+
+func TestScanBatches(t *testing.T) {
+	const numCookies = 2500 // more than one batch
+	s, err := firefox.Open(newLargeDB(t, numCookies), nil)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+	dbtest.ScanBatches(t, s, numCookies)
+}
+
+func TestKeyed(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	a := cookies.C{Name: "a", Value: "1", Domain: "example.com", Path: "/", Expires: now.Add(time.Hour), Created: now, SameSite: cookies.Lax}
+	b := cookies.C{Name: "b", Value: "2", Domain: "example.com", Path: "/", Expires: now.Add(time.Hour), Created: now, SameSite: cookies.Lax}
+	s, err := firefox.Open(newTestDB(t, a), nil)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+	var _ cookies.KeyedStore = s
+
+	mustGet := func(key cookies.Key, want cookies.C) {
+		t.Helper()
+		got, err := s.Get(key)
+		if err != nil {
+			t.Fatalf("Get %v: unexpected error: %v", key, err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("Get %v: (-want, +got)\n%s", key, diff)
+		}
+	}
+
+	mustGet(a.Key(), a)
+	if _, err := s.Get(b.Key()); !errors.Is(err, cookies.ErrNotFound) {
+		t.Errorf("Get %v: got %v, want %v", b.Key(), err, cookies.ErrNotFound)
+	}
+
+	// Put of a new key inserts a cookie.
+	if err := s.Put(b); err != nil {
+		t.Fatalf("Put %v: %v", b.Key(), err)
+	}
+	mustGet(b.Key(), b)
+
+	// Put of an existing key replaces the cookie.
+	a.Value = "updated"
+	a.Flags.Secure = true
+	a.SameSite = cookies.Strict
+	if err := s.Put(a); err != nil {
+		t.Fatalf("Put %v: %v", a.Key(), err)
+	}
+	mustGet(a.Key(), a)
+	if diff := cmp.Diff([]string{"a", "b"}, dbtest.ScanNames(t, s)); diff != "" {
+		t.Errorf("Names after Put (-want, +got):\n%s", diff)
+	}
+
+	if err := s.Delete(a.Key()); err != nil {
+		t.Fatalf("Delete %v: %v", a.Key(), err)
+	}
+	if err := s.Delete(a.Key()); err != nil {
+		t.Errorf("Delete %v again: %v", a.Key(), err)
+	}
+	if diff := cmp.Diff([]string{"b"}, dbtest.ScanNames(t, s)); diff != "" {
+		t.Errorf("Names after Delete (-want, +got):\n%s", diff)
+	}
+}
+
+func TestScanContext(t *testing.T) {
+	s, err := firefox.Open(newTestDB(t,
+		cookies.C{Name: "a", Domain: "example.com", Path: "/"},
+		cookies.C{Name: "b", Domain: "example.com", Path: "/"},
+		cookies.C{Name: "c", Domain: "example.com", Path: "/"},
+	), nil)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+	var _ cookies.ContextStore = s
+
+	// Cancel the scan partway through. None of the discards should be applied.
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	var n int
+	err = s.ScanContext(ctx, func(e cookies.Editor) (cookies.Action, error) {
+		if n++; n == 2 {
+			cancel()
+		}
+		return cookies.Discard, nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("ScanContext: got error %v, want %v", err, context.Canceled)
+	}
+	if n != 2 {
+		t.Errorf("ScanContext: visited %d cookies, want 2", n)
+	}
+	if diff := cmp.Diff([]string{"a", "b", "c"}, dbtest.ScanNames(t, s)); diff != "" {
+		t.Errorf("Names after cancel (-want, +got):\n%s", diff)
+	}
+}
+
+func TestAll(t *testing.T) {
+	s, err := firefox.Open(newTestDB(t,
+		cookies.C{Name: "a", Domain: "example.com", Path: "/"},
+		cookies.C{Name: "b", Domain: "example.com", Path: "/"},
+		cookies.C{Name: "c", Domain: "example.com", Path: "/"},
+	), &firefox.Options{ReadOnly: true})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+	var _ cookies.IterStore = s
+
+	var got []string
+	for c, err := range s.All() {
+		if err != nil {
+			t.Fatalf("All: unexpected error: %v", err)
+		}
+		got = append(got, c.Name)
+		if c.Name == "b" {
+			break
+		}
+	}
+	if diff := cmp.Diff([]string{"a", "b"}, got); diff != "" {
+		t.Errorf("All (-want, +got):\n%s", diff)
+	}
+}
+
+func TestAttrs(t *testing.T) {
+	s, err := firefox.Open(newTestDB(t, cookies.C{Name: "a", Domain: "example.com", Path: "/"}), nil)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+
+	if err := s.Scan(func(e cookies.Editor) (cookies.Action, error) {
+		ae, ok := e.(cookies.AttrEditor)
+		if !ok {
+			t.Fatalf("Editor %T does not implement AttrEditor", e)
+		}
+		if diff := cmp.Diff(map[string]any{"originAttributes": ""}, ae.Attrs()); diff != "" {
+			t.Errorf("Attrs (-want, +got):\n%s", diff)
+		}
+		if err := ae.SetAttr("nonesuch", 1); err == nil {
+			t.Error("SetAttr unknown name: got nil, want error")
+		}
+		if err := ae.SetAttr("originAttributes", true); err == nil {
+			t.Error("SetAttr wrong type: got nil, want error")
+		}
+		if err := ae.SetAttr("originAttributes", "^userContextId=1"); err != nil {
+			t.Errorf("SetAttr: unexpected error: %v", err)
+		}
+		return cookies.Update, nil
+	}); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+
+	// Verify that the update was stored.
+	if err := s.Scan(func(e cookies.Editor) (cookies.Action, error) {
+		got := e.(cookies.AttrEditor).Attrs()
+		if diff := cmp.Diff(map[string]any{"originAttributes": "^userContextId=1"}, got); diff != "" {
+			t.Errorf("Attrs after update (-want, +got):\n%s", diff)
+		}
+		return cookies.Keep, nil
+	}); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+}
+
+func TestKeyedContainers(t *testing.T) {
+	exp := time.Now().Add(time.Hour).Truncate(time.Second)
+	a := cookies.C{Name: "a", Value: "default", Domain: "example.com", Path: "/", Expires: exp}
+	path := newTestDB(t, a)
+
+	// Add a cookie with the same key in another container.
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("Open database: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO moz_cookies (originAttributes, name, value, host, path, expiry, creationTime, isSecure, isHttpOnly) `+
+		`VALUES ('^userContextId=1', 'a', 'other', 'example.com', '/', ?, 0, 0, 0)`, exp.Unix()); err != nil {
+		t.Fatalf("Insert cookie: %v", err)
+	}
+	db.Close()
+
+	s, err := firefox.Open(path, nil)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+
+	values := func() []string {
+		t.Helper()
+		var out []string
+		for c, err := range s.All() {
+			if err != nil {
+				t.Fatalf("All: unexpected error: %v", err)
+			}
+			out = append(out, c.Value)
+		}
+		return out
+	}
+
+	// Get, Put, and Delete see only the cookie in the default container.
+	if got, err := s.Get(a.Key()); err != nil || got.Value != "default" {
+		t.Errorf("Get: got (%v, %v), want value default", got, err)
+	}
+	a.Value = "updated"
+	if err := s.Put(a); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if diff := cmp.Diff([]string{"updated", "other"}, values()); diff != "" {
+		t.Errorf("After Put (-want, +got):\n%s", diff)
+	}
+	if err := s.Delete(a.Key()); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if diff := cmp.Diff([]string{"other"}, values()); diff != "" {
+		t.Errorf("After Delete (-want, +got):\n%s", diff)
+	}
+	if got, err := s.Get(a.Key()); !errors.Is(err, cookies.ErrNotFound) {
+		t.Errorf("Get after Delete: got (%v, %v), want %v", got, err, cookies.ErrNotFound)
+	}
+
+	// Put of a missing key adds it to the default container.
+	if err := s.Put(a); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if diff := cmp.Diff([]string{"other", "updated"}, values()); diff != "" {
+		t.Errorf("After second Put (-want, +got):\n%s", diff)
+	}
+}
+
+func TestSessionCookie(t *testing.T) {
+	exp := time.Now().Add(time.Hour).Truncate(time.Second)
+	s, err := firefox.Open(newTestDB(t, cookies.C{Name: "a", Domain: "example.com", Path: "/", Expires: exp}), nil)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+
+	if err := s.Put(cookies.C{Name: "s", Domain: "example.com", Path: "/"}); !errors.Is(err, cookies.ErrInvalid) {
+		t.Errorf("Put session cookie: got %v, want %v", err, cookies.ErrInvalid)
+	}
+	if err := s.Scan(func(e cookies.Editor) (cookies.Action, error) {
+		c := e.Get()
+		c.Expires = time.Time{}
+		if err := e.Set(c); !errors.Is(err, cookies.ErrInvalid) {
+			t.Errorf("Set session cookie: got %v, want %v", err, cookies.ErrInvalid)
+		}
+		return cookies.Keep, nil
+	}); err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+}
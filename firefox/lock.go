@@ -0,0 +1,90 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package firefox
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/creachadair/cookies"
+	"github.com/creachadair/cookies/internal/dbfile"
+)
+
+// checkLock reports an error wrapping [cookies.ErrLocked] if the browser that
+// owns the cookie database at path appears to be running.
+//
+// Firefox keeps its locks in the profile directory: On Linux, "lock" is a
+// symbolic link that exists only while the browser runs; on Windows the same
+// is true of "parent.lock". On macOS and Linux, ".parentlock" may persist
+// after exit, so it counts only while another process holds a lock on it.
+// A "lock" link left behind by a browser that crashed is ignored.
+func checkLock(path string) error {
+	dir := filepath.Dir(path)
+	for _, name := range []string{"lock", "parent.lock"} {
+		if lock := filepath.Join(dir, name); dbfile.Exists(lock) && !isStale(lock) {
+			return fmt.Errorf("%w: found %s", cookies.ErrLocked, lock)
+		}
+	}
+	lock := filepath.Join(dir, ".parentlock")
+	if held, err := dbfile.LockHeld(lock); err != nil {
+		return fmt.Errorf("checking %s: %w", lock, err)
+	} else if held {
+		return fmt.Errorf("%w: %s is held", cookies.ErrLocked, lock)
+	}
+	return nil
+}
+
+// isStale reports whether lock is a symbolic link whose target names a
+// process on this host that is no longer running. The target of the link has
+// the form "address:+pid", where address is an IP address of the host that
+// created it. A lock created by another host is never stale, since there is
+// no way to check whether its process is running.
+func isStale(lock string) bool {
+	target, err := os.Readlink(lock)
+	if err != nil {
+		return false
+	}
+	addr, spid, ok := strings.Cut(target, ":+")
+	if !ok || !isLocalAddr(addr) {
+		return false
+	}
+	pid, err := strconv.Atoi(spid)
+	return err == nil && dbfile.ProcessExited(pid)
+}
+
+// isLocalAddr reports whether addr is a loopback address or an address of
+// one of the network interfaces of this host.
+func isLocalAddr(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	} else if ip.IsLoopback() {
+		return true
+	}
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return false
+	}
+	for _, a := range addrs {
+		if n, ok := a.(*net.IPNet); ok && n.IP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,214 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package har reads cookies from HTTP Archive (HAR) 1.2 files, as exported by
+// browser developer tools and HTTP proxies.
+//
+// A HAR file records the cookies set by each response with their attributes.
+// Replaying them in order recovers the state of the browser's cookie jar at
+// the end of the session:
+//
+//	cs, err := har.ReadCookies("bug-report.har")
+//	if cs == nil && err != nil {
+//		log.Fatalf("Reading HAR: %v", err)
+//	} else if err != nil {
+//		log.Printf("Skipped cookies: %v", err)
+//	}
+//	for _, c := range cs {
+//		if err := store.Put(c); err != nil {
+//			log.Fatalf("Adding cookie: %v", err)
+//		}
+//	}
+//
+// Cookies sent by requests are not included, since the HAR records only their
+// names and values.
+package har
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/creachadair/cookies"
+)
+
+// A HAR is the parsed contents of a HAR file. Only the fields needed to
+// recover cookies are decoded.
+type HAR struct {
+	Log struct {
+		Version string   `json:"version"`
+		Entries []*Entry `json:"entries"`
+	} `json:"log"`
+}
+
+// An Entry is a single request and its response.
+type Entry struct {
+	StartedDateTime time.Time `json:"startedDateTime"`
+	Request         struct {
+		Method  string    `json:"method"`
+		URL     string    `json:"url"`
+		Cookies []*Cookie `json:"cookies"`
+	} `json:"request"`
+	Response struct {
+		Status  int       `json:"status"`
+		Cookies []*Cookie `json:"cookies"`
+	} `json:"response"`
+}
+
+// A Cookie is a cookie sent by a request or set by a response.
+type Cookie struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Path     string `json:"path,omitempty"`
+	Domain   string `json:"domain,omitempty"`
+	Expires  string `json:"expires,omitempty"` // ISO 8601
+	HTTPOnly bool   `json:"httpOnly,omitempty"`
+	Secure   bool   `json:"secure,omitempty"`
+	SameSite string `json:"sameSite,omitempty"` // not in HAR 1.2; added by Chrome
+}
+
+// Parse parses the contents of a HAR file.
+func Parse(data []byte) (*HAR, error) {
+	var h HAR
+	if err := json.Unmarshal(data, &h); err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+// ReadCookies reads the HAR file at path and returns its final cookies, as
+// reported by [HAR.Cookies].
+func ReadCookies(path string) ([]cookies.C, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	h, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return h.Cookies()
+}
+
+// Cookies replays the cookies set by the responses of h, in order, and
+// returns the resulting contents of the cookie jar, in the order the cookies
+// were first set.
+//
+// Each cookie is interpreted as by [cookies.ParseSetCookie] for the URL of
+// its request, at the start time of the entry, which is recorded as its
+// creation time. A cookie replaces any earlier cookie with the same key,
+// keeping its creation time, and a cookie that has already expired removes
+// the earlier cookie instead.
+//
+// Cookies that a browser would ignore are skipped. If any were skipped,
+// Cookies returns the remaining cookies together with an error that joins the
+// reasons for each skipped cookie. The cookies of an entry with an invalid
+// request URL or no start time are skipped in the same way.
+func (h *HAR) Cookies() ([]cookies.C, error) {
+	var jar []cookies.C
+	var errs []error
+	find := func(key cookies.Key) int {
+		return slices.IndexFunc(jar, func(c cookies.C) bool { return c.Key() == key })
+	}
+	for i, e := range h.Log.Entries {
+		if len(e.Response.Cookies) == 0 {
+			continue
+		}
+		u, err := url.Parse(e.Request.URL)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("entry %d: invalid URL: %w", i, err))
+			continue
+		}
+		now := e.StartedDateTime
+		if now.IsZero() {
+			errs = append(errs, fmt.Errorf("entry %d: missing start time", i))
+			continue
+		}
+		for _, hc := range e.Response.Cookies {
+			c, err := hc.cookie(u, now)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("entry %d: cookie %q: %w", i, hc.Name, err))
+				continue
+			}
+			j := find(c.Key())
+			if !c.Expires.IsZero() && !c.Expires.After(now) {
+				if j >= 0 {
+					jar = slices.Delete(jar, j, j+1)
+				}
+			} else if j >= 0 {
+				c.Created = jar[j].Created
+				jar[j] = c
+			} else {
+				jar = append(jar, c)
+			}
+		}
+	}
+	return jar, errors.Join(errs...)
+}
+
+// cookie returns the cookie stored by a browser that received c in response
+// to a request for u at now.
+//
+// The attributes of c are interpreted by [cookies.ParseSetCookie], but its
+// name and value are taken as recorded, so that a value containing ";" is
+// reported as invalid rather than truncated as it would be in a header.
+func (c *Cookie) cookie(u *url.URL, now time.Time) (cookies.C, error) {
+	var sb strings.Builder
+	sb.WriteString(c.Name)
+	sb.WriteByte('=')
+	if c.Domain != "" {
+		fmt.Fprintf(&sb, "; Domain=%s", c.Domain)
+	}
+	if c.Path != "" {
+		fmt.Fprintf(&sb, "; Path=%s", c.Path)
+	}
+	if t, ok := parseExpires(c.Expires); ok {
+		fmt.Fprintf(&sb, "; Expires=%s", t.UTC().Format(http.TimeFormat))
+	}
+	if c.Secure {
+		sb.WriteString("; Secure")
+	}
+	if c.HTTPOnly {
+		sb.WriteString("; HttpOnly")
+	}
+	if c.SameSite != "" {
+		fmt.Fprintf(&sb, "; SameSite=%s", c.SameSite)
+	}
+	out, err := cookies.ParseSetCookie(sb.String(), u, now)
+	if err != nil {
+		return cookies.C{}, err
+	}
+	out.Name, out.Value = c.Name, c.Value
+	if err := cookies.Validate(out); err != nil {
+		return cookies.C{}, err
+	}
+	return out, nil
+}
+
+// parseExpires parses the expiration time of a HAR cookie. HAR 1.2 specifies
+// ISO 8601, but some tools record the HTTP date of the Expires attribute.
+func parseExpires(s string) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, true
+	} else if t, err := http.ParseTime(s); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,160 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package har_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/creachadair/cookies"
+	"github.com/creachadair/cookies/har"
+	"github.com/google/go-cmp/cmp"
+)
+
+const testHAR = `{
+  "log": {
+    "version": "1.2",
+    "creator": {"name": "WebInspector", "version": "537.36"},
+    "entries": [
+      {
+        "startedDateTime": "2026-06-01T12:00:00.000Z",
+        "request": {"method": "POST", "url": "https://www.example.com/login", "cookies": []},
+        "response": {
+          "status": 302,
+          "cookies": [
+            {"name": "sid", "value": "one", "domain": ".example.com", "path": "/",
+             "expires": "2027-01-01T00:00:00.000Z", "httpOnly": true, "secure": true, "sameSite": "Lax"},
+            {"name": "tmp", "value": "x", "path": "/", "expires": null, "httpOnly": false, "secure": false},
+            {"name": "pref", "value": "dark"},
+            {"name": "bad", "value": "1", "domain": "other.com"}
+          ]
+        }
+      },
+      {
+        "startedDateTime": "2026-06-01T12:00:01.000Z",
+        "request": {"method": "GET", "url": "https://www.example.com/app/home",
+                    "cookies": [{"name": "sid", "value": "one"}]},
+        "response": {"status": 200, "cookies": []}
+      },
+      {
+        "startedDateTime": "2026-06-01T12:05:00.000Z",
+        "request": {"method": "GET", "url": "https://www.example.com/app/refresh", "cookies": []},
+        "response": {
+          "status": 200,
+          "cookies": [
+            {"name": "sid", "value": "two", "domain": "example.com", "path": "/",
+             "expires": "2027-01-01T00:00:00.000Z", "httpOnly": true, "secure": true},
+            {"name": "tmp", "value": "", "path": "/", "expires": "1970-01-01T00:00:00.000Z"}
+          ]
+        }
+      }
+    ]
+  }
+}`
+
+func TestCookies(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.har")
+	if err := os.WriteFile(path, []byte(testHAR), 0600); err != nil {
+		t.Fatalf("Write file: %v", err)
+	}
+	got, err := har.ReadCookies(path)
+	if err == nil || !errors.Is(err, cookies.ErrInvalid) {
+		t.Errorf("ReadCookies: got %v, want %v", err, cookies.ErrInvalid)
+	} else if !strings.Contains(err.Error(), `cookie "bad"`) {
+		t.Errorf("ReadCookies: error %q does not mention the skipped cookie", err)
+	}
+
+	start := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	want := []cookies.C{{
+		// Replaced by the third entry, keeping the original creation time.
+		Name: "sid", Value: "two", Domain: "example.com", Path: "/",
+		Expires: time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC), Created: start,
+		Flags: cookies.Flags{Secure: true, HTTPOnly: true},
+	}, {
+		// No domain or path: host-only, with the default path.
+		Name: "pref", Value: "dark", Domain: "www.example.com", Path: "/",
+		Created: start, Flags: cookies.Flags{HostOnly: true},
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Cookies (-want, +got):\n%s", diff)
+	}
+}
+
+func TestCookieValues(t *testing.T) {
+	h, err := har.Parse([]byte(`{"log": {"entries": [{
+		"startedDateTime": "2026-06-01T12:00:00Z",
+		"request": {"url": "https://example.com/"},
+		"response": {"cookies": [
+			{"name": "list", "value": "a,b", "expires": "Fri, 01 Jan 2027 00:00:00 GMT"},
+			{"name": "semi", "value": "a;b"}
+		]}}]}}`))
+	if err != nil {
+		t.Fatalf("Parse: unexpected error: %v", err)
+	}
+	got, err := h.Cookies()
+	if !errors.Is(err, cookies.ErrInvalid) {
+		t.Errorf("Cookies: got error %v, want %v", err, cookies.ErrInvalid)
+	}
+	want := []cookies.C{{
+		Name: "list", Value: "a,b", Domain: "example.com", Path: "/",
+		Expires: time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+		Created: time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC),
+		Flags:   cookies.Flags{HostOnly: true},
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Cookies (-want, +got):\n%s", diff)
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	if _, err := har.Parse([]byte(`{"log": {"entries": [{"startedDateTime": "bogus"}]}}`)); err == nil {
+		t.Error("Parse with invalid time: got nil, want error")
+	}
+
+	// Entries with an invalid URL or no start time are reported and skipped,
+	// but the cookies of other entries are kept.
+	h, err := har.Parse([]byte(`{"log": {"entries": [{
+		"startedDateTime": "2026-06-01T12:00:00Z",
+		"request": {"url": "http://%zz"},
+		"response": {"cookies": [{"name": "a", "value": "1"}]}
+	}, {
+		"request": {"url": "https://example.com/"},
+		"response": {"cookies": [{"name": "b", "value": "2"}]}
+	}, {
+		"startedDateTime": "2026-06-01T12:00:00Z",
+		"request": {"url": "https://example.com/"},
+		"response": {"cookies": [{"name": "c", "value": "3"}]}
+	}]}}`))
+	if err != nil {
+		t.Fatalf("Parse: unexpected error: %v", err)
+	}
+	got, err := h.Cookies()
+	if err == nil {
+		t.Error("Cookies: got nil error, want errors for entries 0 and 1")
+	} else {
+		for _, want := range []string{"entry 0:", "entry 1:"} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("Cookies error %q: missing %q", err, want)
+			}
+		}
+	}
+	if len(got) != 1 || got[0].Name != "c" {
+		t.Errorf("Cookies: got %v, want only cookie c", got)
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package httpx provides an HTTP transport that sends the cookies from a
+// [cookies.Store] with each request, such as the cookies of a user's browser
+// profile.
+//
+// To make requests with the cookies of a Chrome profile:
+//
+//	s, err := chromedb.Open(path, &chromedb.Options{Snapshot: true})
+//	if err != nil {
+//		log.Fatalf("Open: %v", err)
+//	}
+//	defer s.Close()
+//	tr, err := httpx.New(s, nil)
+//	if err != nil {
+//		log.Fatalf("New: %v", err)
+//	}
+//	cli := &http.Client{Transport: tr}
+//	rsp, err := cli.Get("https://example.com/api/whoami")
+//	// ...
+//
+// Because the transport manages cookies itself, the client should not also
+// have a Jar.
+package httpx
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/creachadair/cookies"
+)
+
+// Options are optional settings for a Transport.
+// A nil *Options is ready for use with default settings.
+type Options struct {
+	// The transport used to send requests. If nil, http.DefaultTransport is
+	// used.
+	Base http.RoundTripper
+
+	// If true, cookies set by the Set-Cookie headers of responses are saved
+	// to the store, replacing any existing cookies with the same keys, and
+	// the store is committed. Cookies the server expires are removed. This
+	// requires a store that implements [cookies.KeyedStore] and is not
+	// read-only.
+	Persist bool
+}
+
+func (o *Options) base() http.RoundTripper {
+	if o == nil || o.Base == nil {
+		return http.DefaultTransport
+	}
+	return o.Base
+}
+
+func (o *Options) persist() bool { return o != nil && o.Persist }
+
+// readOnlyStore is implemented by stores that can report whether they were
+// opened read-only.
+type readOnlyStore interface {
+	ReadOnly() bool
+}
+
+// A Transport is an [http.RoundTripper] that adds the cookies from a store to
+// each request, following the matching rules of [cookies.ForURL]. If the
+// store implements [cookies.FilteredStore], only the cookies for the site of
+// each request are read from it.
+//
+// A Transport is safe for concurrent use by multiple goroutines, provided the
+// store is not accessed by other means while the transport is in use.
+type Transport struct {
+	base    http.RoundTripper
+	persist bool
+
+	mu    sync.Mutex
+	store cookies.Store
+}
+
+// New returns a Transport that adds the cookies from s to each request.
+// If opts.Persist is true, s must implement [cookies.KeyedStore], and must not
+// report itself read-only by a ReadOnly method, as the stores of the chromedb,
+// firefox, and webkitgtk packages do; otherwise New reports an error.
+func New(s cookies.Store, opts *Options) (*Transport, error) {
+	if opts.persist() {
+		if _, ok := s.(cookies.KeyedStore); !ok {
+			return nil, fmt.Errorf("store %T does not support Persist", s)
+		} else if ro, ok := s.(readOnlyStore); ok && ro.ReadOnly() {
+			return nil, errors.New("cannot Persist to a read-only store")
+		}
+	}
+	return &Transport{base: opts.base(), persist: opts.persist(), store: s}, nil
+}
+
+// RoundTrip implements the [http.RoundTripper] interface.  Cookies from the
+// store are appended to any Cookie header already present in req, which is
+// not modified.
+//
+// If the transport persists cookies and saving them to the store fails,
+// RoundTrip closes the response body and reports an error.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	cs, err := cookies.ForURL(t.store, req.URL)
+	t.mu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("reading cookies: %w", err)
+	}
+	if len(cs) != 0 {
+		req = req.Clone(req.Context())
+		hdr := cookies.CookieHeader(cs)
+		if old := req.Header.Get("Cookie"); old != "" {
+			hdr = old + "; " + hdr
+		}
+		req.Header.Set("Cookie", hdr)
+	}
+
+	rsp, err := t.base.RoundTrip(req)
+	if err != nil || !t.persist {
+		return rsp, err
+	}
+	if err := t.save(req, rsp); err != nil {
+		rsp.Body.Close()
+		return nil, fmt.Errorf("saving cookies: %w", err)
+	}
+	return rsp, nil
+}
+
+// save updates the store with the cookies set by rsp in reply to req.
+// Set-Cookie headers that a user agent would ignore are skipped.
+func (t *Transport) save(req *http.Request, rsp *http.Response) error {
+	lines := rsp.Header.Values("Set-Cookie")
+	if len(lines) == 0 {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ks := t.store.(cookies.KeyedStore)
+	now := time.Now()
+	for _, line := range lines {
+		c, err := cookies.ParseSetCookie(line, req.URL, now)
+		if err != nil {
+			continue
+		}
+		if !c.Expires.IsZero() && !c.Expires.After(now) {
+			if err := ks.Delete(c.Key()); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// A cookie that replaces an existing one keeps its creation time.
+		if old, err := ks.Get(c.Key()); err == nil {
+			c.Created = old.Created
+		} else if !errors.Is(err, cookies.ErrNotFound) {
+			return err
+		}
+		if err := ks.Put(c); err != nil {
+			return err
+		}
+	}
+	return ks.Commit()
+}
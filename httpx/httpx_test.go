@@ -0,0 +1,200 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpx_test
+
+import (
+	"database/sql"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/creachadair/cookies"
+	"github.com/creachadair/cookies/chromedb"
+	"github.com/creachadair/cookies/httpx"
+	"github.com/creachadair/cookies/storagestate"
+	"github.com/google/go-cmp/cmp"
+
+	_ "modernc.org/sqlite"
+)
+
+func newServer(t *testing.T) (*httptest.Server, *url.URL) {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			w.Header().Add("Set-Cookie", "sid=new; Path=/")
+			w.Header().Add("Set-Cookie", "pref=; Path=/; Max-Age=0")
+			w.Header().Add("Set-Cookie", "bad=1; Domain=other.com")
+		}
+		io.WriteString(w, r.Header.Get("Cookie"))
+	}))
+	t.Cleanup(srv.Close)
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatalf("Parse server URL: %v", err)
+	}
+	return srv, u
+}
+
+func newStore(t *testing.T, path, host string) *storagestate.Store {
+	t.Helper()
+	s := storagestate.New(path)
+	for _, c := range []cookies.C{
+		{Name: "sid", Value: "old", Path: "/"},
+		{Name: "pref", Value: "dark", Path: "/"},
+		{Name: "deep", Value: "1", Path: "/api"},
+		{Name: "secure", Value: "1", Path: "/", Flags: cookies.Flags{Secure: true}},
+		{Name: "other", Value: "1", Path: "/", Domain: "example.com"},
+	} {
+		if c.Domain == "" {
+			c.Domain = host
+			c.Flags.HostOnly = true
+		}
+		c.SameSite = cookies.Lax
+		if err := s.Put(c); err != nil {
+			t.Fatalf("Put %q: %v", c.Name, err)
+		}
+	}
+	return s
+}
+
+func newTransport(t *testing.T, s cookies.Store, opts *httpx.Options) *httpx.Transport {
+	t.Helper()
+	tr, err := httpx.New(s, opts)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return tr
+}
+
+func get(t *testing.T, cli *http.Client, url string, hdr string) string {
+	t.Helper()
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	if hdr != "" {
+		req.Header.Set("Cookie", hdr)
+	}
+	rsp, err := cli.Do(req)
+	if err != nil {
+		t.Fatalf("Get %q: %v", url, err)
+	}
+	defer rsp.Body.Close()
+	body, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		t.Fatalf("Read body: %v", err)
+	}
+	return string(body)
+}
+
+func TestTransport(t *testing.T) {
+	srv, u := newServer(t)
+	s := newStore(t, filepath.Join(t.TempDir(), "state.json"), u.Hostname())
+	cli := &http.Client{Transport: newTransport(t, s, nil)}
+
+	if got, want := get(t, cli, srv.URL+"/api/x", ""), "deep=1; sid=old; pref=dark"; got != want {
+		t.Errorf("Cookie for /api/x: got %q, want %q", got, want)
+	}
+	if got, want := get(t, cli, srv.URL+"/", "x=y"), "x=y; sid=old; pref=dark"; got != want {
+		t.Errorf("Cookie for /: got %q, want %q", got, want)
+	}
+
+	// Without Persist, responses do not change the store.
+	get(t, cli, srv.URL+"/login", "")
+	if got, want := get(t, cli, srv.URL+"/", ""), "sid=old; pref=dark"; got != want {
+		t.Errorf("Cookie after login: got %q, want %q", got, want)
+	}
+}
+
+func TestPersist(t *testing.T) {
+	srv, u := newServer(t)
+	path := filepath.Join(t.TempDir(), "state.json")
+	s := newStore(t, path, u.Hostname())
+	cli := &http.Client{Transport: newTransport(t, s, &httpx.Options{Persist: true})}
+
+	get(t, cli, srv.URL+"/login", "")
+	if got, want := get(t, cli, srv.URL+"/", ""), "sid=new"; got != want {
+		t.Errorf("Cookie after login: got %q, want %q", got, want)
+	}
+
+	// The changes were committed to the file. The format does not record
+	// creation times, and stores an unspecified SameSite policy as Lax.
+	s2, err := storagestate.Open(path)
+	if err != nil {
+		t.Fatalf("Reopen: %v", err)
+	}
+	sid, err := s2.Get(cookies.Key{Domain: u.Hostname(), Name: "sid", Path: "/"})
+	if err != nil {
+		t.Fatalf("Get sid: %v", err)
+	}
+	want := cookies.C{Name: "sid", Value: "new", Domain: u.Hostname(), Path: "/",
+		Flags: cookies.Flags{HostOnly: true}, SameSite: cookies.Lax}
+	if diff := cmp.Diff(want, sid); diff != "" {
+		t.Errorf("Saved cookie (-want, +got):\n%s", diff)
+	}
+}
+
+func TestPersistRequiresKeyedStore(t *testing.T) {
+	if tr, err := httpx.New(struct{ cookies.Store }{}, &httpx.Options{Persist: true}); err == nil {
+		t.Errorf("New with Persist on a non-keyed store: got %v, want error", tr)
+	}
+}
+
+func TestChromeSession(t *testing.T) {
+	srv, u := newServer(t)
+	path := filepath.Join(t.TempDir(), "Cookies")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("Open database: %v", err)
+	}
+	defer db.Close()
+	for _, stmt := range []string{
+		`CREATE TABLE meta (key LONGVARCHAR NOT NULL UNIQUE PRIMARY KEY, value LONGVARCHAR)`,
+		`INSERT INTO meta (key, value) VALUES ('version', '24')`,
+		`CREATE TABLE cookies (
+  creation_utc INTEGER NOT NULL, host_key TEXT NOT NULL, name TEXT NOT NULL,
+  value TEXT NOT NULL, encrypted_value BLOB NOT NULL DEFAULT x'', path TEXT NOT NULL,
+  expires_utc INTEGER NOT NULL, is_secure INTEGER NOT NULL, is_httponly INTEGER NOT NULL,
+  priority INTEGER NOT NULL DEFAULT 1, samesite INTEGER NOT NULL DEFAULT -1)`,
+		// A session cookie for the server, which Chrome stores with expires_utc = 0.
+		`INSERT INTO cookies (creation_utc, host_key, name, value, path, expires_utc, is_secure, is_httponly)
+  VALUES (13300000000000000, '` + u.Hostname() + `', 'sso', 'token', '/', 0, 0, 1)`,
+		// A cookie for another site, which is not sent.
+		`INSERT INTO cookies (creation_utc, host_key, name, value, path, expires_utc, is_secure, is_httponly)
+  VALUES (13300000000000000, '.example.com', 'other', '1', '/', 0, 0, 0)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("Create database: %v", err)
+		}
+	}
+
+	s, err := chromedb.Open(path, &chromedb.Options{ReadOnly: true})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+	cli := &http.Client{Transport: newTransport(t, s, nil)}
+	if got, want := get(t, cli, srv.URL+"/", ""), "sso=token"; got != want {
+		t.Errorf("Cookie: got %q, want %q", got, want)
+	}
+
+	// A read-only store cannot persist cookies.
+	if tr, err := httpx.New(s, &httpx.Options{Persist: true}); err == nil {
+		t.Errorf("New with Persist on a read-only store: got %v, want error", tr)
+	}
+}
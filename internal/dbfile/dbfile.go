@@ -0,0 +1,225 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dbfile provides helpers for working with SQLite cookie databases,
+// which may be in use by a running browser.
+package dbfile
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+)
+
+// URI returns an SQLite URI filename for the database at path.  If readOnly
+// is true, the URI requests a read-only connection that treats the file as
+// immutable, so that no locks are taken and the file is never modified.
+func URI(path string, readOnly bool) string {
+	if !readOnly {
+		return path
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path // e.g., Windows drive letters
+	}
+	u := &url.URL{Scheme: "file", Path: path, RawQuery: "mode=ro&immutable=1"}
+	return u.String()
+}
+
+// sidecars are the suffixes of auxiliary files that SQLite may keep alongside
+// a database, and which must be copied with it to preserve its contents.
+var sidecars = []string{"-wal", "-journal"}
+
+// maxSnapshotTries is the number of times Snapshot will retry the copy if the
+// database changes while it is being copied.
+const maxSnapshotTries = 3
+
+// A Snapshot is a private copy of a database and its log files.
+type Snapshot struct {
+	dir  string
+	Path string // the path of the copied database
+}
+
+// NewSnapshot copies the database at path, along with any write-ahead log or
+// rollback journal, into a new temporary directory. When the copy is opened,
+// SQLite applies the log, giving a consistent view of the database as of the
+// time of the copy.
+//
+// If the database or any of its log files is modified while it is being
+// copied, the copy is retried a few times before giving up. The caller must
+// call Close when the snapshot is no longer needed, to remove the copy.
+func NewSnapshot(path string) (*Snapshot, error) {
+	dir, err := os.MkdirTemp("", "cookies-snapshot-")
+	if err != nil {
+		return nil, err
+	}
+	s := &Snapshot{dir: dir, Path: filepath.Join(dir, filepath.Base(path))}
+	for range maxSnapshotTries {
+		before, err := statFiles(path)
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+		if err := s.copyFrom(path); err != nil {
+			s.Close()
+			return nil, err
+		}
+		after, err := statFiles(path)
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+		if slices.Equal(before, after) {
+			return s, nil
+		}
+	}
+	s.Close()
+	return nil, fmt.Errorf("database %q changed while copying", path)
+}
+
+// A fileState records the size and modification time of a file, or that it
+// does not exist.
+type fileState struct {
+	exists  bool
+	size    int64
+	modTime int64 // nanoseconds since the Unix epoch
+}
+
+// statFiles returns the states of the database at path and its sidecar files,
+// in that order. The database itself must exist.
+func statFiles(path string) ([]fileState, error) {
+	var out []fileState
+	for i, suffix := range append([]string{""}, sidecars...) {
+		fi, err := os.Stat(path + suffix)
+		if err != nil {
+			if i > 0 && errors.Is(err, os.ErrNotExist) {
+				out = append(out, fileState{})
+				continue
+			}
+			return nil, err
+		}
+		out = append(out, fileState{exists: true, size: fi.Size(), modTime: fi.ModTime().UnixNano()})
+	}
+	return out, nil
+}
+
+// copyFrom copies the database at path and its sidecar files into s,
+// replacing any previous copy.
+func (s *Snapshot) copyFrom(path string) error {
+	if err := copyFile(s.Path, path); err != nil {
+		return err
+	}
+	for _, suffix := range sidecars {
+		os.Remove(s.Path + suffix)
+		err := copyFile(s.Path+suffix, path+suffix)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close removes the copied database.
+func (s *Snapshot) Close() error { return os.RemoveAll(s.dir) }
+
+// copyFile copies the contents of the file at src to dst.
+func copyFile(dst, src string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	_, cerr := io.Copy(out, in)
+	return errors.Join(cerr, out.Close())
+}
+
+// Exists reports whether a file or symbolic link exists at path.  Symbolic
+// links are not followed, since browsers use dangling links as lock markers.
+func Exists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// ProcessExited reports whether the process with the given ID on this host is
+// known to have exited. It reports false if the process is running, or if
+// its state cannot be determined.
+func ProcessExited(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return true
+	}
+	return errors.Is(p.Signal(syscall.Signal(0)), os.ErrProcessDone)
+}
+
+// Columns returns the set of column names defined by the specified table.
+func Columns(tx *sql.Tx, table string) (map[string]bool, error) {
+	rows, err := tx.Query(`SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	cols := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		cols[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	} else if len(cols) == 0 {
+		return nil, fmt.Errorf("table %q not found", table)
+	}
+	return cols, nil
+}
+
+// Insert inserts a row into table within tx. The values map column names to
+// their values; columns not defined by the table are skipped, so that callers
+// can supply values for columns that exist only in some schema versions.
+func Insert(tx *sql.Tx, table string, values map[string]any) error {
+	cols, err := Columns(tx, table)
+	if err != nil {
+		return err
+	}
+	var names, marks []string
+	var args []any
+	for name, v := range values {
+		if cols[name] {
+			names = append(names, name)
+			marks = append(marks, "?")
+			args = append(args, v)
+		}
+	}
+	_, err = tx.Exec(`INSERT INTO `+table+` (`+strings.Join(names, ", ")+`) VALUES (`+
+		strings.Join(marks, ", ")+`)`, args...)
+	return err
+}
@@ -0,0 +1,251 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dbtest provides helpers for testing stores backed by SQLite cookie
+// databases. The caller must register the "sqlite" driver.
+package dbtest
+
+import (
+	"database/sql"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/creachadair/cookies"
+)
+
+// A Store is a cookie store that must be closed after use.
+type Store interface {
+	cookies.Store
+	io.Closer
+}
+
+// NewDB creates a database with the given file name in a temporary
+// directory, executes the statements of schema, and returns its path.
+func NewDB(t testing.TB, name string, schema ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("Open test database: %v", err)
+	}
+	defer db.Close()
+	for _, stmt := range schema {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("Create schema: %v", err)
+		}
+	}
+	return path
+}
+
+// Fill adds n rows to the database at path, executing insert with the
+// arguments returned by args for each i from 0 to n-1, in one transaction.
+func Fill(t testing.TB, path string, n int, insert string, args func(i int) []any) {
+	t.Helper()
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("Open database: %v", err)
+	}
+	defer db.Close()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	defer tx.Rollback()
+	stmt, err := tx.Prepare(insert)
+	if err != nil {
+		t.Fatalf("Prepare: %v", err)
+	}
+	defer stmt.Close()
+	for i := range n {
+		if _, err := stmt.Exec(args(i)...); err != nil {
+			t.Fatalf("Insert: %v", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+}
+
+// ScanNames returns the names of the cookies in s, in order.
+func ScanNames(t testing.TB, s cookies.Store) []string {
+	t.Helper()
+	var names []string
+	if err := s.Scan(func(e cookies.Editor) (cookies.Action, error) {
+		names = append(names, e.Get().Name)
+		return cookies.Keep, nil
+	}); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	return names
+}
+
+// ScanBatches checks that a scan of s, which must hold n cookies, visits
+// every cookie and applies all its changes, when n exceeds the number of
+// cookies the store reads at once.
+func ScanBatches(t *testing.T, s cookies.Store, n int) {
+	t.Helper()
+
+	// Discard every other cookie, and update the rest.
+	var i int
+	if err := s.Scan(func(e cookies.Editor) (cookies.Action, error) {
+		i++
+		if i%2 == 0 {
+			return cookies.Discard, nil
+		}
+		c := e.Get()
+		c.Value = "updated"
+		if err := e.Set(c); err != nil {
+			t.Fatalf("Set %q: %v", c.Name, err)
+		}
+		return cookies.Update, nil
+	}); err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if i != n {
+		t.Errorf("Scan visited %d cookies, want %d", i, n)
+	}
+
+	var got int
+	if err := s.Scan(func(e cookies.Editor) (cookies.Action, error) {
+		if c := e.Get(); c.Value != "updated" {
+			t.Errorf("Cookie %q: value is %q, want updated", c.Name, c.Value)
+		}
+		got++
+		return cookies.Keep, nil
+	}); err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if got != n/2 {
+		t.Errorf("After discard: got %d cookies, want %d", got, n/2)
+	}
+}
+
+// BenchmarkScan benchmarks scans of a fresh copy of the database at src,
+// opened by open, with several patterns of changes.
+//
+// For comparison, each Store benchmark has a Baseline that makes the same
+// changes to table directly: It reads every row at once, and executes a
+// separate unprepared statement for each change.
+func BenchmarkScan(b *testing.B, src, table string, open func(path string) (Store, error)) {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		b.Fatalf("Read database: %v", err)
+	}
+
+	for _, bc := range []struct {
+		name string
+		act  func(i int) cookies.Action
+	}{
+		{"KeepAll", func(int) cookies.Action { return cookies.Keep }},
+		{"DiscardHalf", func(i int) cookies.Action {
+			if i%2 == 0 {
+				return cookies.Discard
+			}
+			return cookies.Keep
+		}},
+		{"DiscardAll", func(int) cookies.Action { return cookies.Discard }},
+		{"UpdateAll", func(int) cookies.Action { return cookies.Update }},
+	} {
+		path := filepath.Join(b.TempDir(), filepath.Base(src))
+		reset := func(b *testing.B) {
+			b.StopTimer()
+			defer b.StartTimer()
+			if err := os.WriteFile(path, data, 0600); err != nil {
+				b.Fatalf("Copy database: %v", err)
+			}
+		}
+
+		b.Run(bc.name+"/Store", func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				reset(b)
+				s, err := open(path)
+				if err != nil {
+					b.Fatalf("Open: %v", err)
+				}
+
+				var i int
+				if err := s.Scan(func(e cookies.Editor) (cookies.Action, error) {
+					i++
+					return bc.act(i), nil
+				}); err != nil {
+					b.Fatalf("Scan: %v", err)
+				}
+				s.Close()
+			}
+		})
+
+		b.Run(bc.name+"/Baseline", func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				reset(b)
+				if err := scanBaseline(path, table, bc.act); err != nil {
+					b.Fatalf("Scan: %v", err)
+				}
+			}
+		})
+	}
+}
+
+// scanBaseline applies act to each row of table in the database at path,
+// without batching or prepared statements.
+func scanBaseline(path, table string, act func(i int) cookies.Action) error {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`SELECT rowid, name, value FROM ` + table)
+	if err != nil {
+		return err
+	}
+	type row struct {
+		id          int64
+		name, value string
+	}
+	var all []row
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.id, &r.name, &r.value); err != nil {
+			rows.Close()
+			return err
+		}
+		all = append(all, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i, r := range all {
+		switch act(i + 1) {
+		case cookies.Update:
+			_, err = tx.Exec(`UPDATE `+table+` SET name = ?, value = ? WHERE rowid = ?`, r.name, r.value, r.id)
+		case cookies.Discard:
+			_, err = tx.Exec(`DELETE FROM `+table+` WHERE rowid = ?`, r.id)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,244 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbfile
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"iter"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/creachadair/cookies"
+)
+
+// BatchSize is the maximum number of cookies a Table reads from the database
+// at once while scanning.
+const BatchSize = 1000
+
+// ErrReadOnly is reported for an attempt to change a read-only store.
+var ErrReadOnly = errors.New("store is read-only")
+
+// A Table scans the cookies of a database table in batches of increasing row
+// ID, so that memory use does not grow with the size of the table. The
+// fields describe how cookies of type T are read and written.
+type Table[T cookies.Editor] struct {
+	DB       *sql.DB
+	ReadOnly bool   // if true, changes are reported as ErrReadOnly
+	Filter   Filter // translates a query into SQL conditions
+
+	// Read reads up to limit cookies satisfying the SQL conditions in conds
+	// whose row IDs are greater than after, in order of increasing row ID.
+	Read func(ctx context.Context, tx *sql.Tx, after int64, limit int, conds []string, args []any) ([]T, error)
+
+	// ID returns the row ID of a cookie returned by Read.
+	ID func(T) int64
+
+	// Write stores the current state of a cookie, and Drop deletes it.
+	Write, Drop func(*Writer, T) error
+}
+
+// All returns an iterator over the cookies of the table, read within a
+// read-only transaction.
+func (t *Table[T]) All() iter.Seq2[cookies.C, error] {
+	return func(yield func(cookies.C, error) bool) {
+		ctx := context.Background()
+		tx, err := t.DB.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+		if err != nil {
+			yield(cookies.C{}, err)
+			return
+		}
+		defer tx.Rollback()
+
+		after := int64(math.MinInt64)
+		for {
+			cs, err := t.Read(ctx, tx, after, BatchSize, nil, nil)
+			if err != nil {
+				yield(cookies.C{}, err)
+				return
+			}
+			for _, c := range cs {
+				if !yield(c.Get(), nil) {
+					return
+				}
+			}
+			if len(cs) < BatchSize {
+				return
+			}
+			after = t.ID(cs[len(cs)-1])
+		}
+	}
+}
+
+// Scan calls f for each cookie of the table matching q, and applies the
+// resulting changes in a single transaction. The transaction is committed
+// only if the scan completes without error. If ctx ends before the scan is
+// complete, Scan reports the error from ctx.
+func (t *Table[T]) Scan(ctx context.Context, q cookies.Query, f cookies.ScanFunc) error {
+	tx, err := t.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	w := &Writer{ctx: ctx, tx: tx}
+	defer w.close()
+
+	conds, args := t.Filter.Conds(q)
+	after := int64(math.MinInt64)
+	for {
+		cs, err := t.Read(ctx, tx, after, BatchSize, conds, args)
+		if err != nil {
+			return err
+		}
+		for _, c := range cs {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			act, err := f(c)
+			if err != nil {
+				return err
+			}
+			switch act {
+			case cookies.Keep:
+				continue
+
+			case cookies.Update:
+				if t.ReadOnly {
+					return ErrReadOnly
+				}
+				if err := t.Write(w, c); err != nil {
+					return err
+				}
+
+			case cookies.Discard:
+				if t.ReadOnly {
+					return ErrReadOnly
+				}
+				if err := t.Drop(w, c); err != nil {
+					return err
+				}
+
+			default:
+				return fmt.Errorf("unknown action %v", act)
+			}
+		}
+		if len(cs) < BatchSize {
+			break
+		}
+		after = t.ID(cs[len(cs)-1])
+	}
+	return tx.Commit()
+}
+
+// A Writer applies changes within a transaction, preparing each statement
+// the first time it is needed and reusing it thereafter.
+type Writer struct {
+	ctx   context.Context
+	tx    *sql.Tx
+	stmts map[string]*sql.Stmt
+}
+
+// Exec executes query with the given arguments.
+func (w *Writer) Exec(query string, args ...any) error {
+	stmt, ok := w.stmts[query]
+	if !ok {
+		p, err := w.tx.PrepareContext(w.ctx, query)
+		if err != nil {
+			return err
+		}
+		if w.stmts == nil {
+			w.stmts = make(map[string]*sql.Stmt)
+		}
+		w.stmts[query] = p
+		stmt = p
+	}
+	_, err := stmt.ExecContext(w.ctx, args...)
+	return err
+}
+
+// close releases any statements prepared by w.
+func (w *Writer) close() {
+	for _, stmt := range w.stmts {
+		stmt.Close()
+	}
+}
+
+// A Filter describes the columns of a cookie table, for translating a query
+// into SQL conditions.
+type Filter struct {
+	Host    string // the column holding the host key
+	Name    string // the column holding the name
+	Expires string // the column holding the expiration time
+
+	// ExpiresValue encodes a time for comparison with the Expires column.
+	ExpiresValue func(time.Time) int64
+
+	// If true, session cookies are stored with an expiration of 0, and are
+	// treated as never expiring.
+	ZeroSession bool
+}
+
+// Conds returns SQL conditions and their arguments to select the cookies
+// matching q. If q is empty, there are no conditions.
+func (f Filter) Conds(q cookies.Query) ([]string, []any) {
+	var conds []string
+	var args []any
+	if q.Domain != "" {
+		d := EscapeLike(strings.ToLower(strings.TrimPrefix(q.Domain, ".")))
+		conds = append(conds, fmt.Sprintf(`(%[1]s LIKE ? ESCAPE '\' OR %[1]s LIKE ? ESCAPE '\' OR %[1]s LIKE ? ESCAPE '\')`, f.Host))
+		args = append(args, d, "."+d, "%."+d)
+	}
+	if q.Name != "" {
+		conds = append(conds, f.Name+` = ?`)
+		args = append(args, q.Name)
+	}
+	if !q.ExpiresBefore.IsZero() {
+		if f.ZeroSession {
+			conds = append(conds, fmt.Sprintf(`(%[1]s != 0 AND %[1]s < ?)`, f.Expires))
+		} else {
+			conds = append(conds, f.Expires+` < ?`)
+		}
+		args = append(args, f.ExpiresValue(q.ExpiresBefore))
+	}
+	if !q.ExpiresAfter.IsZero() {
+		if f.ZeroSession {
+			conds = append(conds, fmt.Sprintf(`(%[1]s = 0 OR %[1]s >= ?)`, f.Expires))
+		} else {
+			conds = append(conds, f.Expires+` >= ?`)
+		}
+		args = append(args, f.ExpiresValue(q.ExpiresAfter))
+	}
+	return conds, args
+}
+
+// CeilUnix returns t in seconds since the Unix epoch, rounded up. For a table
+// that stores expiration times in whole seconds, this makes a bound agree
+// with comparisons on the decoded values.
+func CeilUnix(t time.Time) int64 {
+	if t.Nanosecond() != 0 {
+		return t.Unix() + 1
+	}
+	return t.Unix()
+}
+
+// EscapeLike escapes the wildcard characters of an SQL LIKE pattern in s,
+// using backslash as the escape character.
+func EscapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
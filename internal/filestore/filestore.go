@@ -0,0 +1,171 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package filestore provides the in-memory part of a cookie store whose
+// contents are read from a file all at once, and written back in full when
+// the store is committed.
+package filestore
+
+import (
+	"fmt"
+	"io"
+	"iter"
+
+	"github.com/creachadair/atomicfile"
+	"github.com/creachadair/cookies"
+)
+
+// Cookie is the constraint on the cookies in a List: A pointer to a
+// format-specific cookie type, which satisfies [cookies.Editor].
+type Cookie[T any] interface {
+	*T
+	cookies.Editor
+}
+
+// A List is an ordered collection of format-specific cookies. It provides the
+// methods of the [cookies.Store], [cookies.IterStore], and [cookies.KeyedStore]
+// interfaces, except that Commit requires the caller to supply the path and
+// encoding of the file. The zero value is an empty list ready for use.
+type List[T any, P Cookie[T]] struct {
+	Cookies []P  // in file order
+	Dirty   bool // whether Cookies has changed since it was last written
+
+	// If non-nil, Copy returns a copy of c that can be modified without
+	// affecting c. If nil, a shallow copy of *c is used.
+	Copy func(c P) P
+
+	// If non-nil, New returns a new cookie with the contents of c, for a Put
+	// whose key is not already in the list. If nil, the Set method of a zero
+	// cookie is used.
+	New func(c cookies.C) (P, error)
+}
+
+func (l *List[T, P]) copy(c P) P {
+	if l.Copy != nil {
+		return l.Copy(c)
+	}
+	tmp := *c
+	return &tmp
+}
+
+func (l *List[T, P]) newCookie(c cookies.C) (P, error) {
+	if l.New != nil {
+		return l.New(c)
+	}
+	var nc P = new(T)
+	if err := nc.Set(c); err != nil {
+		return nil, err
+	}
+	return nc, nil
+}
+
+// Scan calls f for a copy of each cookie in l, in order, and applies the
+// resulting actions as described by [cookies.Store].
+func (l *List[T, P]) Scan(f cookies.ScanFunc) error {
+	var out []P
+	for _, c := range l.Cookies {
+		// Edit a copy of the cookie so that changes can be discarded if the
+		// action is Keep.
+		tmp := l.copy(c)
+		act, err := f(tmp)
+		if err != nil {
+			return err
+		}
+		switch act {
+		case cookies.Keep:
+			out = append(out, c) // discard changes
+		case cookies.Update:
+			out = append(out, tmp) // include updates
+			l.Dirty = true
+		case cookies.Discard:
+			l.Dirty = true // discard entirely
+		default:
+			return fmt.Errorf("unknown action: %v", act)
+		}
+	}
+	l.Cookies = out
+	return nil
+}
+
+// All returns an iterator over the cookies in l, in order. It never reports
+// an error.
+func (l *List[T, P]) All() iter.Seq2[cookies.C, error] {
+	return func(yield func(cookies.C, error) bool) {
+		for _, c := range l.Cookies {
+			if !yield(c.Get(), nil) {
+				return
+			}
+		}
+	}
+}
+
+// find returns the index of the cookie in l with the given key, or -1.
+func (l *List[T, P]) find(key cookies.Key) int {
+	for i, c := range l.Cookies {
+		if c.Get().Key() == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// Get returns the cookie in l with the given key, or [cookies.ErrNotFound].
+func (l *List[T, P]) Get(key cookies.Key) (cookies.C, error) {
+	if i := l.find(key); i >= 0 {
+		return l.Cookies[i].Get(), nil
+	}
+	return cookies.C{}, cookies.ErrNotFound
+}
+
+// Put replaces the cookie in l with the same key as c, or adds a new cookie
+// at the end of l if there is none. A replaced cookie is updated by its Set
+// method, so it keeps any format-specific fields that Set does not change.
+func (l *List[T, P]) Put(c cookies.C) error {
+	if i := l.find(c.Key()); i >= 0 {
+		tmp := l.copy(l.Cookies[i])
+		if err := tmp.Set(c); err != nil {
+			return err
+		}
+		l.Cookies[i] = tmp
+	} else {
+		nc, err := l.newCookie(c)
+		if err != nil {
+			return err
+		}
+		l.Cookies = append(l.Cookies, nc)
+	}
+	l.Dirty = true
+	return nil
+}
+
+// Delete removes the cookie in l with the given key, if there is one.
+func (l *List[T, P]) Delete(key cookies.Key) error {
+	if i := l.find(key); i >= 0 {
+		l.Cookies = append(l.Cookies[:i:i], l.Cookies[i+1:]...)
+		l.Dirty = true
+	}
+	return nil
+}
+
+// Commit atomically replaces the file at path with the output of write, if l
+// has changed since it was last written.
+func (l *List[T, P]) Commit(path string, write func(io.Writer) error) error {
+	if l.Dirty {
+		if err := atomicfile.Tx(path, 0600, write); err != nil {
+			return err
+		}
+		l.Dirty = false
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filestore_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/creachadair/cookies"
+	"github.com/creachadair/cookies/internal/filestore"
+	"github.com/google/go-cmp/cmp"
+)
+
+// testCookie is a minimal format-specific cookie, with one field that is not
+// represented in cookies.C.
+type testCookie struct {
+	cookies.C
+	Tag string
+}
+
+func (c *testCookie) Get() cookies.C { return c.C }
+
+func (c *testCookie) Set(o cookies.C) error {
+	if err := cookies.Validate(o); err != nil {
+		return err
+	}
+	c.C = o
+	return nil
+}
+
+type list = filestore.List[testCookie, *testCookie]
+
+func names(l *list) string {
+	var out []string
+	for c := range l.All() {
+		out = append(out, c.Name+"="+c.Value)
+	}
+	return strings.Join(out, " ")
+}
+
+func newList() *list {
+	l := new(list)
+	for _, name := range []string{"a", "b", "c"} {
+		l.Cookies = append(l.Cookies, &testCookie{
+			C:   cookies.C{Name: name, Value: "1", Domain: "example.com", Path: "/"},
+			Tag: name,
+		})
+	}
+	return l
+}
+
+func TestScan(t *testing.T) {
+	l := newList()
+
+	// Edits to a cookie are discarded unless the action is Update.
+	if err := l.Scan(func(e cookies.Editor) (cookies.Action, error) {
+		c := e.Get()
+		c.Value = "2"
+		if err := e.Set(c); err != nil {
+			return 0, err
+		}
+		switch c.Name {
+		case "a":
+			return cookies.Update, nil
+		case "b":
+			return cookies.Discard, nil
+		default:
+			return cookies.Keep, nil
+		}
+	}); err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if got, want := names(l), "a=2 c=1"; got != want {
+		t.Errorf("After Scan: got %q, want %q", got, want)
+	}
+	if !l.Dirty {
+		t.Error("After Scan: list is not dirty")
+	}
+
+	// An error from the callback stops the scan with no changes.
+	bad := errors.New("bad")
+	if err := l.Scan(func(cookies.Editor) (cookies.Action, error) {
+		return cookies.Discard, bad
+	}); !errors.Is(err, bad) {
+		t.Errorf("Scan: got %v, want %v", err, bad)
+	}
+	if err := l.Scan(func(cookies.Editor) (cookies.Action, error) {
+		return cookies.Action(99), nil
+	}); err == nil {
+		t.Error("Scan with unknown action: got nil, want error")
+	}
+	if got, want := names(l), "a=2 c=1"; got != want {
+		t.Errorf("After failed Scan: got %q, want %q", got, want)
+	}
+}
+
+func TestKeyed(t *testing.T) {
+	l := newList()
+	key := cookies.Key{Domain: ".example.com", Name: "b", Path: "/"}
+
+	if got, err := l.Get(key); err != nil || got.Value != "1" {
+		t.Errorf("Get %v: got (%v, %v), want value 1", key, got, err)
+	}
+	if _, err := l.Get(cookies.Key{Name: "nonesuch"}); !errors.Is(err, cookies.ErrNotFound) {
+		t.Errorf("Get missing: got %v, want %v", err, cookies.ErrNotFound)
+	}
+
+	// Replacing a cookie keeps its format-specific fields.
+	if err := l.Put(cookies.C{Name: "b", Value: "2", Domain: "example.com", Path: "/"}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if got := l.Cookies[1].Tag; got != "b" {
+		t.Errorf("After Put: tag is %q, want b", got)
+	}
+
+	if err := l.Put(cookies.C{Name: "a;b", Domain: "example.com"}); err == nil {
+		t.Error("Put invalid cookie: got nil, want error")
+	}
+
+	// New cookies are added at the end, using New if it is set.
+	l.New = func(c cookies.C) (*testCookie, error) {
+		return &testCookie{C: c, Tag: "new"}, nil
+	}
+	if err := l.Put(cookies.C{Name: "d", Value: "1", Domain: "example.com", Path: "/"}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if got := l.Cookies[3].Tag; got != "new" {
+		t.Errorf("After Put: tag is %q, want new", got)
+	}
+
+	if err := l.Delete(key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := l.Delete(key); err != nil {
+		t.Errorf("Delete again: %v", err)
+	}
+	if got, want := names(l), "a=1 c=1 d=1"; got != want {
+		t.Errorf("After Delete: got %q, want %q", got, want)
+	}
+}
+
+func TestCommit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cookies")
+	l := newList()
+	var writes int
+	write := func(w io.Writer) error {
+		writes++
+		_, err := fmt.Fprint(w, names(l))
+		return err
+	}
+
+	// A list that has not changed is not written.
+	if err := l.Commit(path, write); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	if writes != 0 {
+		t.Errorf("Commit of a clean list wrote %d times", writes)
+	}
+
+	l.Delete(cookies.Key{Domain: ".example.com", Name: "a", Path: "/"})
+	if err := l.Commit(path, write); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Read file: %v", err)
+	}
+	if diff := cmp.Diff("b=1 c=1", string(data)); diff != "" {
+		t.Errorf("File (-want, +got):\n%s", diff)
+	}
+	if l.Dirty {
+		t.Error("After Commit: list is still dirty")
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package openstore opens browser cookie stores by file name, for use by the
+// command-line tools. The caller must register the "sqlite" driver.
+package openstore
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/creachadair/cookies"
+	"github.com/creachadair/cookies/bincookie"
+	"github.com/creachadair/cookies/chromedb"
+	"github.com/creachadair/cookies/firefox"
+	"github.com/creachadair/cookies/internal/dbfile"
+	"github.com/creachadair/cookies/webkitgtk"
+)
+
+// Open opens a cookie store for the specified path. The type of the
+// contents is inferred from the filename. Firefox and WebKitGTK browsers both
+// name their databases "cookies.sqlite", so these are told apart by schema.
+func Open(path string) (cookies.Store, error) {
+	if filepath.Ext(path) == ".binarycookies" {
+		return bincookie.Open(path)
+	}
+	p := strings.ToLower(path)
+	if strings.Contains(p, "google") && filepath.Base(p) == "cookies" {
+		return chromedb.Open(path, nil)
+	}
+	if filepath.Base(p) == "cookies.sqlite" {
+		isWebKit, err := isWebKitDB(path)
+		if err != nil {
+			return nil, err
+		} else if isWebKit {
+			return webkitgtk.Open(path, nil)
+		}
+		return firefox.Open(path, nil)
+	}
+	return nil, errors.New("unknown file type")
+}
+
+// isWebKitDB reports whether the SQLite database at path has the WebKitGTK
+// cookie schema. The database is opened read-only to check.
+func isWebKitDB(path string) (bool, error) {
+	if _, err := os.Stat(path); err != nil {
+		return false, err
+	}
+	db, err := sql.Open("sqlite", dbfile.URI(path, true))
+	if err != nil {
+		return false, err
+	}
+	defer db.Close()
+	return webkitgtk.IsSchema(db)
+}
@@ -0,0 +1,60 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openstore_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/creachadair/cookies/firefox"
+	"github.com/creachadair/cookies/internal/dbfile/dbtest"
+	"github.com/creachadair/cookies/internal/openstore"
+	"github.com/creachadair/cookies/webkitgtk"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestOpen(t *testing.T) {
+	ffPath := dbtest.NewDB(t, "cookies.sqlite", `CREATE TABLE moz_cookies (
+  id INTEGER PRIMARY KEY, originAttributes TEXT NOT NULL DEFAULT '',
+  name TEXT, value TEXT, host TEXT, path TEXT, expiry INTEGER,
+  lastAccessed INTEGER, creationTime INTEGER, isSecure INTEGER, isHttpOnly INTEGER,
+  sameSite INTEGER DEFAULT 0)`)
+	wkPath := dbtest.NewDB(t, "cookies.sqlite", `CREATE TABLE moz_cookies (
+  id INTEGER PRIMARY KEY, name TEXT, value TEXT, host TEXT, path TEXT,
+  expiry INTEGER, lastAccessed INTEGER, isSecure INTEGER, isHttpOnly INTEGER, sameSite INTEGER)`)
+
+	s, err := openstore.Open(ffPath)
+	if err != nil {
+		t.Fatalf("Open Firefox: %v", err)
+	}
+	if _, ok := s.(*firefox.Store); !ok {
+		t.Errorf("Open Firefox: got %T, want *firefox.Store", s)
+	}
+	s.(io.Closer).Close()
+
+	s, err = openstore.Open(wkPath)
+	if err != nil {
+		t.Fatalf("Open WebKitGTK: %v", err)
+	}
+	if _, ok := s.(*webkitgtk.Store); !ok {
+		t.Errorf("Open WebKitGTK: got %T, want *webkitgtk.Store", s)
+	}
+	s.(io.Closer).Close()
+
+	if s, err := openstore.Open("cookies.txt"); err == nil {
+		t.Errorf("Open unknown: got %T, want error", s)
+	}
+}
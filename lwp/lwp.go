@@ -0,0 +1,541 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lwp supports reading and modifying cookie files in the format
+// written by the LWPCookieJar class of the Python http.cookiejar module.
+//
+// An LWP file begins with a "#LWP-Cookies-2.0" line, followed by one line for
+// each cookie in the form of a "Set-Cookie3:" header:
+//
+//	#LWP-Cookies-2.0
+//	Set-Cookie3: sid=abc; path="/"; domain=".example.com"; path_spec; expires="2027-01-01 00:00:00Z"; HttpOnly=None; version=0
+//
+// Attributes that have no counterpart in [cookies.C], such as "discard",
+// "version", "port", and "path_spec", are preserved when the file is written
+// back, and are exposed as format-specific attributes (see [Cookie]).
+package lwp
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/creachadair/cookies"
+	"github.com/creachadair/cookies/internal/filestore"
+)
+
+const (
+	magic  = "#LWP-Cookies-2.0"
+	header = "Set-Cookie3:"
+
+	// expiresFormat is the format of expiration times, as written by
+	// http.cookiejar.time2isoz.
+	expiresFormat = "2006-01-02 15:04:05Z"
+)
+
+// A Cookie is a single cookie in the LWP format.
+//
+// A Cookie also satisfies [cookies.AttrEditor], with the following attributes:
+//
+//	discard    -- bool, whether the cookie is discarded at the end of the session
+//	version    -- string, the cookie version, usually "0"; "" is written as "0"
+//	port       -- string, a comma-separated list of ports, or ""
+//	port_spec  -- bool, whether the port was specified by the server
+//	path_spec  -- bool, whether the path was specified by the server
+//	domain_dot -- bool, whether the domain specified by the server began with "."
+//	comment    -- string, a comment from the server
+//	commenturl -- string, a comment URL from the server
+type Cookie struct {
+	Name   string
+	Value  string
+	Domain string // with a leading period for domain cookies
+	Path   string
+
+	Port      string
+	PortSpec  bool
+	PathSpec  bool
+	DomainDot bool
+	Secure    bool
+
+	Expires time.Time // zero if the cookie has no expiration time
+	Discard bool
+
+	Comment    string
+	CommentURL string
+	Version    string
+
+	// Other attributes of the cookie, such as "HttpOnly" and "SameSite", keyed
+	// by name. Python records an attribute without a value as "None".
+	Rest map[string]string
+}
+
+// Get returns a format-independent representation of c.
+// It satisfies part of [cookies.Editor].
+func (c *Cookie) Get() cookies.C {
+	domain, hostOnly := cookies.ParseHostKey(c.Domain)
+	out := cookies.C{
+		Name:    c.Name,
+		Value:   c.Value,
+		Domain:  domain,
+		Path:    c.Path,
+		Expires: c.Expires,
+		Flags: cookies.Flags{
+			Secure:   c.Secure,
+			HostOnly: hostOnly,
+		},
+	}
+	if _, ok := c.rest("HttpOnly"); ok {
+		out.Flags.HTTPOnly = true
+	}
+	if v, ok := c.rest("SameSite"); ok {
+		out.SameSite = decodeSitePolicy(v)
+	}
+	return out
+}
+
+// Set updates c to match the contents of o. The format does not record the
+// creation time of a cookie, so o.Created is discarded. A cookie is marked to
+// be discarded if and only if it has no expiration time, as Python does;
+// otherwise, the LWP-specific fields of c are not changed.
+// It satisfies part of [cookies.Editor].
+func (c *Cookie) Set(o cookies.C) error {
+	if err := cookies.Validate(o); err != nil {
+		return err
+	}
+	c.Name = o.Name
+	c.Value = o.Value
+	c.Domain = o.HostKey()
+	c.Path = o.Path
+	c.Secure = o.Flags.Secure
+	c.Expires = o.Expires
+	c.Discard = o.Expires.IsZero()
+	if c.Version == "" {
+		c.Version = "0"
+	}
+	c.setRest("HttpOnly", "None", o.Flags.HTTPOnly)
+	c.setRest("SameSite", o.SameSite.String(), o.SameSite != cookies.Unknown)
+	return nil
+}
+
+// rest returns the value of the named attribute of c from Rest, ignoring
+// case, and reports whether it was present.
+func (c *Cookie) rest(name string) (string, bool) {
+	for k, v := range c.Rest {
+		if strings.EqualFold(k, name) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// setRest removes the named attribute of c from Rest, ignoring case, and
+// then adds it with the given value if ok is true.
+func (c *Cookie) setRest(name, value string, ok bool) {
+	maps.DeleteFunc(c.Rest, func(k, _ string) bool { return strings.EqualFold(k, name) })
+	if ok {
+		if c.Rest == nil {
+			c.Rest = make(map[string]string)
+		}
+		c.Rest[name] = value
+	}
+}
+
+func decodeSitePolicy(s string) cookies.SameSite {
+	switch strings.ToLower(s) {
+	case "strict":
+		return cookies.Strict
+	case "lax":
+		return cookies.Lax
+	case "none":
+		return cookies.None
+	default:
+		return cookies.Unknown
+	}
+}
+
+// Attrs returns the LWP-specific attributes of c.
+// It satisfies part of [cookies.AttrEditor].
+func (c *Cookie) Attrs() map[string]any {
+	return map[string]any{
+		"discard":    c.Discard,
+		"version":    c.Version,
+		"port":       c.Port,
+		"port_spec":  c.PortSpec,
+		"path_spec":  c.PathSpec,
+		"domain_dot": c.DomainDot,
+		"comment":    c.Comment,
+		"commenturl": c.CommentURL,
+	}
+}
+
+// SetAttr sets the named LWP-specific attribute of c.
+// It satisfies part of [cookies.AttrEditor].
+func (c *Cookie) SetAttr(name string, value any) error {
+	switch name {
+	case "discard", "port_spec", "path_spec", "domain_dot":
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("attribute %q: got %T, want bool", name, value)
+		}
+		switch name {
+		case "discard":
+			c.Discard = v
+		case "port_spec":
+			c.PortSpec = v
+		case "path_spec":
+			c.PathSpec = v
+		case "domain_dot":
+			c.DomainDot = v
+		}
+	case "version", "port", "comment", "commenturl":
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("attribute %q: got %T, want string", name, value)
+		}
+		switch name {
+		case "version":
+			c.Version = v
+		case "port":
+			c.Port = v
+		case "comment":
+			c.Comment = v
+		case "commenturl":
+			c.CommentURL = v
+		}
+	default:
+		return fmt.Errorf("unknown attribute %q", name)
+	}
+	return nil
+}
+
+// String returns the encoding of c as a Set-Cookie3 line, without the
+// header, in the form written by http.cookiejar.lwp_cookie_str.
+func (c *Cookie) String() string {
+	var parts []string
+	add := func(key, value string) { parts = append(parts, key+"="+quote(value)) }
+	flag := func(key string, ok bool) {
+		if ok {
+			parts = append(parts, key)
+		}
+	}
+	add(c.Name, c.Value)
+	add("path", c.Path)
+	add("domain", c.Domain)
+	if c.Port != "" {
+		add("port", c.Port)
+	}
+	flag("path_spec", c.PathSpec)
+	flag("port_spec", c.PortSpec)
+	flag("domain_dot", c.DomainDot)
+	flag("secure", c.Secure)
+	if !c.Expires.IsZero() {
+		add("expires", c.Expires.UTC().Format(expiresFormat))
+	}
+	flag("discard", c.Discard)
+	if c.Comment != "" {
+		add("comment", c.Comment)
+	}
+	if c.CommentURL != "" {
+		add("commenturl", c.CommentURL)
+	}
+	for _, k := range slices.Sorted(maps.Keys(c.Rest)) {
+		add(k, c.Rest[k])
+	}
+	version := c.Version
+	if version == "" {
+		version = "0" // Python requires an integer
+	}
+	add("version", version)
+	return strings.Join(parts, "; ")
+}
+
+// quote returns s as written by http.cookiejar.join_header_words: Unchanged
+// if it consists only of word characters, and otherwise quoted with
+// backslash escapes.
+func quote(s string) string {
+	if s != "" && strings.IndexFunc(s, notWord) < 0 {
+		return s
+	}
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range s {
+		if r == '"' || r == '\\' {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+func notWord(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' }
+
+// ParseLine parses a Set-Cookie3 line, with or without the header, and
+// returns the cookies it defines. A line written by Python defines a single
+// cookie, but the format permits several, separated by commas.
+func ParseLine(line string) ([]*Cookie, error) {
+	line = strings.TrimSpace(strings.TrimPrefix(line, header))
+	var out []*Cookie
+	for _, words := range splitHeaderWords(line) {
+		c, err := parseWords(words)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+// parseWords constructs a cookie from the name-value pairs of a Set-Cookie3
+// line, following http.cookiejar.LWPCookieJar.
+func parseWords(words []word) (*Cookie, error) {
+	c := &Cookie{Name: words[0].key, Value: words[0].value}
+	for _, w := range words[1:] {
+		switch strings.ToLower(w.key) {
+		case "path":
+			c.Path = w.value
+		case "domain":
+			c.Domain = w.value
+		case "port":
+			c.Port = w.value
+		case "expires":
+			t, err := time.Parse(expiresFormat, w.value)
+			if err != nil {
+				return nil, fmt.Errorf("cookie %q: invalid expiration time %q", c.Name, w.value)
+			}
+			c.Expires = t
+		case "comment":
+			c.Comment = w.value
+		case "commenturl":
+			c.CommentURL = w.value
+		case "version":
+			c.Version = w.value
+		case "path_spec":
+			c.PathSpec = true
+		case "port_spec":
+			c.PortSpec = true
+		case "domain_dot":
+			c.DomainDot = true
+		case "secure":
+			c.Secure = true
+		case "discard":
+			c.Discard = true
+		default:
+			if c.Rest == nil {
+				c.Rest = make(map[string]string)
+			}
+			v := w.value
+			if !w.hasValue {
+				v = "None"
+			}
+			c.Rest[w.key] = v
+		}
+	}
+	if c.Domain == "" {
+		return nil, fmt.Errorf("cookie %q has no domain", c.Name)
+	}
+	if c.Expires.IsZero() {
+		c.Discard = true
+	}
+	return c, nil
+}
+
+// A word is a single name-value pair of a header.
+type word struct {
+	key, value string
+	hasValue   bool
+}
+
+// splitHeaderWords splits a header value into groups of name-value pairs,
+// following http.cookiejar.split_header_words. Groups are separated by
+// commas, and pairs by semicolons; values may be quoted.
+func splitHeaderWords(s string) [][]word {
+	var out [][]word
+	var cur []word
+	for s != "" {
+		s = strings.TrimLeft(s, " \t")
+		if n := strings.IndexAny(s, "= \t;,"); n != 0 && s != "" {
+			if n < 0 {
+				n = len(s)
+			}
+			w := word{key: s[:n]}
+			s = s[n:]
+			if rest := strings.TrimLeft(s, " \t"); strings.HasPrefix(rest, "=") {
+				w.value, s, w.hasValue = parseValue(strings.TrimLeft(rest[1:], " \t"))
+			}
+			cur = append(cur, w)
+		} else if strings.HasPrefix(s, ",") {
+			s = s[1:]
+			if len(cur) != 0 {
+				out = append(out, cur)
+				cur = nil
+			}
+		} else {
+			s = strings.TrimLeft(s, "= \t;")
+		}
+	}
+	if len(cur) != 0 {
+		out = append(out, cur)
+	}
+	return out
+}
+
+// parseValue parses a quoted or unquoted value at the beginning of s, and
+// returns the value and the remainder of s.
+func parseValue(s string) (value, rest string, ok bool) {
+	if strings.HasPrefix(s, `"`) {
+		var sb strings.Builder
+		for i := 1; i < len(s); i++ {
+			switch s[i] {
+			case '\\':
+				if i+1 < len(s) {
+					i++
+					sb.WriteByte(s[i])
+				}
+			case '"':
+				return sb.String(), s[i+1:], true
+			default:
+				sb.WriteByte(s[i])
+			}
+		}
+		// An unterminated quote is treated as an unquoted value, as by Python.
+	}
+	n := strings.IndexAny(s, " \t;,")
+	if n < 0 {
+		n = len(s)
+	}
+	return s[:n], s[n:], true
+}
+
+// Open opens an LWP cookie file and returns a Store containing its data.
+func Open(path string) (*Store, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	cs, err := Parse(f)
+	if err != nil {
+		return nil, err
+	}
+	s := newStore(path)
+	s.list.Cookies = cs
+	return s, nil
+}
+
+// Parse parses the contents of an LWP cookie file. Unlike Python, Parse
+// retains cookies that are expired or marked to be discarded.
+func Parse(r io.Reader) ([]*Cookie, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	if !sc.Scan() || !strings.HasPrefix(sc.Text(), "#LWP-Cookies-") {
+		if err := sc.Err(); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("not an LWP cookie file")
+	}
+	var out []*Cookie
+	for ln := 2; sc.Scan(); ln++ {
+		if !strings.HasPrefix(sc.Text(), header) {
+			continue
+		}
+		cs, err := ParseLine(sc.Text())
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", ln, err)
+		}
+		out = append(out, cs...)
+	}
+	return out, sc.Err()
+}
+
+// New returns an empty Store that will be written to path by Commit.
+func New(path string) *Store {
+	s := newStore(path)
+	s.list.Dirty = true
+	return s
+}
+
+// newStore returns an empty Store for path.
+func newStore(path string) *Store {
+	s := &Store{path: path}
+	s.list.Copy = func(c *Cookie) *Cookie {
+		tmp := *c
+		tmp.Rest = maps.Clone(c.Rest)
+		return &tmp
+	}
+	s.list.New = func(c cookies.C) (*Cookie, error) {
+		nc := &Cookie{PathSpec: c.Path != ""}
+		if err := nc.Set(c); err != nil {
+			return nil, err
+		}
+		return nc, nil
+	}
+	return s
+}
+
+// A Store represents an LWP cookie file, and satisfies the
+// [cookies.KeyedStore] and [cookies.IterStore] interfaces.
+type Store struct {
+	path string
+	list filestore.List[Cookie, *Cookie]
+}
+
+// Cookies returns the cookies in s, in the order they appear in the file.
+func (s *Store) Cookies() []*Cookie { return s.list.Cookies }
+
+// WriteTo encodes the cookies in s in LWP format to w.
+func (s *Store) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	buf.WriteString(magic + "\n")
+	for _, c := range s.list.Cookies {
+		fmt.Fprintf(&buf, "%s %s\n", header, c)
+	}
+	return buf.WriteTo(w)
+}
+
+// Scan implements part of the [cookies.Store] interface.  Unlike Python, Scan
+// visits cookies that are expired or marked to be discarded.
+func (s *Store) Scan(f cookies.ScanFunc) error { return s.list.Scan(f) }
+
+// All implements the [cookies.IterStore] interface, reporting the cookies in
+// file order.
+func (s *Store) All() iter.Seq2[cookies.C, error] { return s.list.All() }
+
+// Get implements part of the [cookies.KeyedStore] interface.
+func (s *Store) Get(key cookies.Key) (cookies.C, error) { return s.list.Get(key) }
+
+// Put implements part of the [cookies.KeyedStore] interface.  An existing
+// cookie keeps its LWP-specific attributes, apart from discard (see
+// [Cookie.Set]); a new cookie has path_spec set if it has a path.
+func (s *Store) Put(c cookies.C) error { return s.list.Put(c) }
+
+// Delete implements part of the [cookies.KeyedStore] interface.
+func (s *Store) Delete(key cookies.Key) error { return s.list.Delete(key) }
+
+// Commit implements part of the [cookies.Store] interface.
+func (s *Store) Commit() error {
+	return s.list.Commit(s.path, func(w io.Writer) error {
+		_, err := s.WriteTo(w)
+		return err
+	})
+}
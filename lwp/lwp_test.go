@@ -0,0 +1,196 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lwp_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/creachadair/cookies"
+	"github.com/creachadair/cookies/lwp"
+	"github.com/google/go-cmp/cmp"
+)
+
+// testFile was written by http.cookiejar.LWPCookieJar.save.
+const testFile = `#LWP-Cookies-2.0
+Set-Cookie3: sid=abc123; path="/"; domain=".example.com"; path_spec; domain_dot; secure; expires="2027-01-01 00:00:00Z"; HttpOnly=None; SameSite=Lax; version=0
+Set-Cookie3: pref="dark mode"; path="/app"; domain="www.example.com"; expires="2026-10-18 16:22:26Z"; version=0
+Set-Cookie3: tmp=1; path="/app"; domain="www.example.com"; port="80,8080"; port_spec; discard; comment="say \"hi\""; version=1
+`
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cookies.lwp")
+	if err := os.WriteFile(path, []byte(testFile), 0600); err != nil {
+		t.Fatalf("Write file: %v", err)
+	}
+	s, err := lwp.Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	var _ cookies.KeyedStore = s
+	var _ cookies.IterStore = s
+
+	want := []cookies.C{{
+		Name: "sid", Value: "abc123", Domain: "example.com", Path: "/",
+		Expires:  time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+		Flags:    cookies.Flags{Secure: true, HTTPOnly: true},
+		SameSite: cookies.Lax,
+	}, {
+		Name: "pref", Value: "dark mode", Domain: "www.example.com", Path: "/app",
+		Expires: time.Date(2026, 10, 18, 16, 22, 26, 0, time.UTC),
+		Flags:   cookies.Flags{HostOnly: true},
+	}, {
+		Name: "tmp", Value: "1", Domain: "www.example.com", Path: "/app",
+		Flags: cookies.Flags{HostOnly: true},
+	}}
+	var got []cookies.C
+	for c, err := range s.All() {
+		if err != nil {
+			t.Fatalf("All: unexpected error: %v", err)
+		}
+		got = append(got, c)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Cookies (-want, +got):\n%s", diff)
+	}
+
+	wantAttrs := map[string]any{
+		"discard": true, "version": "1", "port": "80,8080", "port_spec": true,
+		"path_spec": false, "domain_dot": false, "comment": `say "hi"`, "commenturl": "",
+	}
+	if diff := cmp.Diff(wantAttrs, s.Cookies()[2].Attrs()); diff != "" {
+		t.Errorf("Attrs (-want, +got):\n%s", diff)
+	}
+
+	// Writing the file back without changes reproduces it exactly.
+	var buf bytes.Buffer
+	if _, err := s.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+	if diff := cmp.Diff(testFile, buf.String()); diff != "" {
+		t.Errorf("WriteTo (-want, +got):\n%s", diff)
+	}
+
+	// Update one cookie, discard another, and add a third.
+	want[0].Value = "xyz"
+	want[0].SameSite = cookies.Strict
+	want[0].Flags.HTTPOnly = false
+	if err := s.Put(want[0]); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := s.Delete(want[1].Key()); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	added := cookies.C{Name: "new", Value: "1", Domain: "example.org", Path: "/",
+		Flags: cookies.Flags{HTTPOnly: true}}
+	if err := s.Put(added); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := s.Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Read file: %v", err)
+	}
+	lines := strings.Split(string(data), "\n")
+	wantLines := []string{
+		"#LWP-Cookies-2.0",
+		`Set-Cookie3: sid=xyz; path="/"; domain=".example.com"; path_spec; domain_dot; secure; expires="2027-01-01 00:00:00Z"; SameSite=Strict; version=0`,
+		strings.Split(testFile, "\n")[3],
+		`Set-Cookie3: new=1; path="/"; domain=".example.org"; path_spec; discard; HttpOnly=None; version=0`,
+		"",
+	}
+	if diff := cmp.Diff(wantLines, lines); diff != "" {
+		t.Errorf("Updated file (-want, +got):\n%s", diff)
+	}
+}
+
+func TestSessionToPersistent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cookies.lwp")
+	if err := os.WriteFile(path, []byte(testFile), 0600); err != nil {
+		t.Fatalf("Write file: %v", err)
+	}
+	s, err := lwp.Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	// Replace the session cookie "tmp" with one that expires. It should no
+	// longer be marked to be discarded, or Python would not load it.
+	c, err := s.Get(cookies.Key{Domain: "www.example.com", Name: "tmp", Path: "/app"})
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	c.Expires = time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := s.Put(c); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if got := s.Cookies()[2].Attrs()["discard"]; got != false {
+		t.Errorf("After Put: discard is %v, want false", got)
+	}
+	if got := s.Cookies()[2].String(); strings.Contains(got, "discard") {
+		t.Errorf("After Put: cookie is %q, want no discard", got)
+	}
+}
+
+func TestParseLine(t *testing.T) {
+	cs, err := lwp.ParseLine(`Set-Cookie3: a=1; path=/; domain=x.com; Flag, b = "2;3" ; domain=y.com ;; version=0`)
+	if err != nil {
+		t.Fatalf("ParseLine: unexpected error: %v", err)
+	}
+	var got []string
+	for _, c := range cs {
+		got = append(got, c.String())
+	}
+	want := []string{
+		`a=1; path="/"; domain="x.com"; discard; Flag=None; version=0`,
+		`b="2;3"; path=""; domain="y.com"; discard; version=0`,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ParseLine (-want, +got):\n%s", diff)
+	}
+
+	for _, bad := range []string{
+		`a=1; path="/"`,
+		`a=1; domain=x.com; expires="tomorrow"`,
+	} {
+		if cs, err := lwp.ParseLine(bad); err == nil {
+			t.Errorf("ParseLine(%q): got %v, want error", bad, cs)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cookies.lwp")
+	if err := lwp.New(path).Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	s, err := lwp.Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if n := len(s.Cookies()); n != 0 {
+		t.Errorf("New store has %d cookies, want 0", n)
+	}
+	if _, err := lwp.Parse(strings.NewReader("# Netscape HTTP Cookie File\n")); err == nil {
+		t.Error("Parse of a non-LWP file: got nil, want error")
+	}
+}
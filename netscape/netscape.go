@@ -0,0 +1,250 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package netscape supports reading and modifying cookie files in the
+// Netscape "cookies.txt" format, as written by curl, wget, and the
+// MozillaCookieJar class of the Python http.cookiejar module.
+//
+// Each line of the file describes one cookie with seven tab-separated fields:
+// The domain, whether the cookie applies to subdomains ("TRUE" or "FALSE"),
+// the path, whether the cookie is secure, the expiration time in seconds
+// since the Unix epoch, the name, and the value. A line for an HttpOnly
+// cookie has a "#HttpOnly_" prefix. Other lines beginning with "#" are
+// comments.
+//
+// The format does not record the SameSite policy or the creation time of a
+// cookie.
+package netscape
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/creachadair/cookies"
+	"github.com/creachadair/cookies/internal/filestore"
+)
+
+const (
+	magic          = "# Netscape HTTP Cookie File"
+	httpOnlyPrefix = "#HttpOnly_"
+)
+
+// A Cookie is a single cookie in the Netscape format.
+type Cookie struct {
+	Domain     string // with a leading period for domain cookies
+	Subdomains bool   // whether the cookie applies to subdomains
+	Path       string
+	Secure     bool
+	Expires    int64 // seconds since the Unix epoch, or 0 for a session cookie
+	Name       string
+	Value      string
+	HTTPOnly   bool
+}
+
+// Get returns a format-independent representation of c.
+// It satisfies part of [cookies.Editor].
+func (c *Cookie) Get() cookies.C {
+	out := cookies.C{
+		Name:   c.Name,
+		Value:  c.Value,
+		Domain: strings.TrimPrefix(c.Domain, "."),
+		Path:   c.Path,
+		Flags: cookies.Flags{
+			Secure:   c.Secure,
+			HTTPOnly: c.HTTPOnly,
+			HostOnly: !c.Subdomains,
+		},
+	}
+	if c.Expires > 0 {
+		out.Expires = time.Unix(c.Expires, 0).UTC()
+	}
+	return out
+}
+
+// Set updates c to match the contents of o. The format does not record the
+// creation time or SameSite policy of a cookie, so o.Created and o.SameSite
+// are discarded, and the expiration time is truncated to the second.
+//
+// Besides the checks of [cookies.Validate], Set reports an error wrapping
+// [cookies.ErrInvalid] if the domain of o begins with "#" or "$", since a
+// line beginning with either is read back as a comment.
+// It satisfies part of [cookies.Editor].
+func (c *Cookie) Set(o cookies.C) error {
+	if err := cookies.Validate(o); err != nil {
+		return err
+	} else if strings.HasPrefix(o.Domain, "#") || strings.HasPrefix(o.Domain, "$") {
+		return fmt.Errorf("%w: domain %q would be read as a comment", cookies.ErrInvalid, o.Domain)
+	}
+	*c = Cookie{
+		Domain:     o.HostKey(),
+		Subdomains: !o.Flags.HostOnly,
+		Path:       o.Path,
+		Secure:     o.Flags.Secure,
+		Name:       o.Name,
+		Value:      o.Value,
+		HTTPOnly:   o.Flags.HTTPOnly,
+	}
+	if !o.Expires.IsZero() {
+		c.Expires = max(o.Expires.Unix(), 1)
+	}
+	return nil
+}
+
+// String returns the encoding of c as a line of a cookie file, without a
+// trailing newline. Session cookies have an empty expiration time, as
+// written by Python.
+func (c *Cookie) String() string {
+	var prefix, expires string
+	if c.HTTPOnly {
+		prefix = httpOnlyPrefix
+	}
+	if c.Expires > 0 {
+		expires = strconv.FormatInt(c.Expires, 10)
+	}
+	return strings.Join([]string{
+		prefix + c.Domain, boolString(c.Subdomains), c.Path, boolString(c.Secure),
+		expires, c.Name, c.Value,
+	}, "\t")
+}
+
+func boolString(b bool) string {
+	if b {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+// ParseLine parses a single line of a cookie file. It reports nil without
+// error for a blank or comment line.
+func ParseLine(line string) (*Cookie, error) {
+	line = strings.TrimRight(line, "\r\n")
+	var c Cookie
+	if rest, ok := strings.CutPrefix(line, httpOnlyPrefix); ok {
+		line, c.HTTPOnly = rest, true
+	} else if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "$") {
+		return nil, nil
+	}
+	f := strings.Split(line, "\t")
+	if len(f) != 7 {
+		return nil, fmt.Errorf("got %d fields, want 7", len(f))
+	}
+	c.Domain, c.Path, c.Name, c.Value = f[0], f[2], f[5], f[6]
+	c.Subdomains = strings.EqualFold(f[1], "TRUE")
+	c.Secure = strings.EqualFold(f[3], "TRUE")
+	if f[4] != "" {
+		v, err := strconv.ParseInt(f[4], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expiration time %q", f[4])
+		}
+		c.Expires = max(v, 0)
+	}
+	return &c, nil
+}
+
+// Parse parses the contents of a cookie file.  Unlike Python, Parse does not
+// require the file to begin with a "# Netscape HTTP Cookie File" line, and
+// retains expired and session cookies.
+func Parse(r io.Reader) ([]*Cookie, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	var out []*Cookie
+	for ln := 1; sc.Scan(); ln++ {
+		c, err := ParseLine(sc.Text())
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", ln, err)
+		} else if c != nil {
+			out = append(out, c)
+		}
+	}
+	return out, sc.Err()
+}
+
+// Open opens a cookie file and returns a Store containing its data.
+func Open(path string) (*Store, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	cs, err := Parse(f)
+	if err != nil {
+		return nil, err
+	}
+	s := &Store{path: path}
+	s.list.Cookies = cs
+	return s, nil
+}
+
+// New returns an empty Store that will be written to path by Commit.
+func New(path string) *Store {
+	s := &Store{path: path}
+	s.list.Dirty = true
+	return s
+}
+
+// A Store represents a cookies.txt file, and satisfies the
+// [cookies.KeyedStore] and [cookies.IterStore] interfaces.
+type Store struct {
+	path string
+	list filestore.List[Cookie, *Cookie]
+}
+
+// Cookies returns the cookies in s, in the order they appear in the file.
+func (s *Store) Cookies() []*Cookie { return s.list.Cookies }
+
+// WriteTo encodes the cookies in s to w. Comments in the original file are
+// not preserved.
+func (s *Store) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	buf.WriteString(magic + "\n\n")
+	for _, c := range s.list.Cookies {
+		buf.WriteString(c.String())
+		buf.WriteByte('\n')
+	}
+	return buf.WriteTo(w)
+}
+
+// Scan implements part of the [cookies.Store] interface.
+func (s *Store) Scan(f cookies.ScanFunc) error { return s.list.Scan(f) }
+
+// All implements the [cookies.IterStore] interface, reporting the cookies in
+// file order.
+func (s *Store) All() iter.Seq2[cookies.C, error] { return s.list.All() }
+
+// Get implements part of the [cookies.KeyedStore] interface.
+func (s *Store) Get(key cookies.Key) (cookies.C, error) { return s.list.Get(key) }
+
+// Put implements part of the [cookies.KeyedStore] interface.  Since the format
+// has no fields beyond those of [cookies.C], the SameSite policy and creation
+// time of c are not kept.
+func (s *Store) Put(c cookies.C) error { return s.list.Put(c) }
+
+// Delete implements part of the [cookies.KeyedStore] interface.
+func (s *Store) Delete(key cookies.Key) error { return s.list.Delete(key) }
+
+// Commit implements part of the [cookies.Store] interface.  Comments in the
+// original file are not preserved.
+func (s *Store) Commit() error {
+	return s.list.Commit(s.path, func(w io.Writer) error {
+		_, err := s.WriteTo(w)
+		return err
+	})
+}
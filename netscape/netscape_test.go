@@ -0,0 +1,134 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package netscape_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/creachadair/cookies"
+	"github.com/creachadair/cookies/netscape"
+	"github.com/google/go-cmp/cmp"
+)
+
+const testFile = "# Netscape HTTP Cookie File\n" +
+	"# This is a generated file!  Do not edit.\n" +
+	"\n" +
+	"#HttpOnly_.example.com\tTRUE\t/\tTRUE\t1798761600\tsid\tabc123\n" +
+	"www.example.com\tFALSE\t/app\tFALSE\t\tpref\tdark mode\n" +
+	"example.org\tTRUE\t/\tFALSE\t0\tempty\t\n"
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cookies.txt")
+	if err := os.WriteFile(path, []byte(testFile), 0600); err != nil {
+		t.Fatalf("Write file: %v", err)
+	}
+	s, err := netscape.Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	var _ cookies.KeyedStore = s
+	var _ cookies.IterStore = s
+
+	want := []cookies.C{{
+		Name: "sid", Value: "abc123", Domain: "example.com", Path: "/",
+		Expires: time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+		Flags:   cookies.Flags{Secure: true, HTTPOnly: true},
+	}, {
+		Name: "pref", Value: "dark mode", Domain: "www.example.com", Path: "/app",
+		Flags: cookies.Flags{HostOnly: true},
+	}, {
+		// A domain cookie written without a leading period, as by some tools.
+		Name: "empty", Domain: "example.org", Path: "/",
+	}}
+	var got []cookies.C
+	for c, err := range s.All() {
+		if err != nil {
+			t.Fatalf("All: unexpected error: %v", err)
+		}
+		got = append(got, c)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Cookies (-want, +got):\n%s", diff)
+	}
+
+	// Update one cookie, discard another, and add a third.
+	want[0].Value = "xyz"
+	want[0].Flags.HTTPOnly = false
+	if err := s.Put(want[0]); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := s.Delete(want[1].Key()); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	added := cookies.C{Name: "new", Value: "1", Domain: "example.net", Path: "/",
+		Expires: time.Date(2027, 1, 1, 0, 0, 0, 5e8, time.UTC),
+		Flags:   cookies.Flags{HostOnly: true, HTTPOnly: true}, SameSite: cookies.Strict}
+	if err := s.Put(added); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := s.Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Read file: %v", err)
+	}
+	wantLines := []string{
+		"# Netscape HTTP Cookie File",
+		"",
+		".example.com\tTRUE\t/\tTRUE\t1798761600\tsid\txyz",
+		"example.org\tTRUE\t/\tFALSE\t\tempty\t",
+		"#HttpOnly_example.net\tFALSE\t/\tFALSE\t1798761600\tnew\t1",
+		"",
+	}
+	if diff := cmp.Diff(wantLines, strings.Split(string(data), "\n")); diff != "" {
+		t.Errorf("Updated file (-want, +got):\n%s", diff)
+	}
+}
+
+func TestParseLine(t *testing.T) {
+	for _, blank := range []string{"", "  ", "# comment", "$ special"} {
+		if c, err := netscape.ParseLine(blank); c != nil || err != nil {
+			t.Errorf("ParseLine(%q): got %v, %v; want nil, nil", blank, c, err)
+		}
+	}
+	for _, bad := range []string{
+		"example.com\tTRUE\t/\tFALSE\t0\tname",
+		"example.com\tTRUE\t/\tFALSE\tnever\tname\tvalue",
+	} {
+		if c, err := netscape.ParseLine(bad); err == nil {
+			t.Errorf("ParseLine(%q): got %v, want error", bad, c)
+		}
+	}
+}
+
+func TestSetInvalid(t *testing.T) {
+	for _, domain := range []string{"#example.com", "$example.com"} {
+		c := &netscape.Cookie{Name: "ok", Domain: "example.com", Path: "/"}
+		err := c.Set(cookies.C{Name: "a", Value: "1", Domain: domain, Path: "/", Flags: cookies.Flags{HostOnly: true}})
+		if !errors.Is(err, cookies.ErrInvalid) {
+			t.Errorf("Set domain %q: got %v, want %v", domain, err, cookies.ErrInvalid)
+		}
+		if c.Name != "ok" {
+			t.Errorf("After failed Set: name is %q, want ok", c.Name)
+		}
+	}
+}
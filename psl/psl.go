@@ -0,0 +1,229 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package psl implements lookups in the Public Suffix List, which identifies
+// the domains under which Internet users can directly register names, such as
+// "com" and "co.uk". The registrable domain of a host name, also called its
+// eTLD+1, is the public suffix plus one more label, and is the usual unit for
+// grouping cookies by site.
+//
+// A copy of the list is embedded in the package, and is returned by
+// [Default]. To refresh the embedded copy, run "go generate" in this
+// directory. To use a different version of the list at run time, load it
+// with [Parse].
+//
+// See https://publicsuffix.org/ for details of the list and its format.
+package psl
+
+import (
+	"bufio"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+)
+
+//go:generate curl -sSfL -o public_suffix_list.dat https://publicsuffix.org/list/public_suffix_list.dat
+
+//go:embed public_suffix_list.dat
+var defaultList string
+
+// Default returns the embedded copy of the Public Suffix List.
+var Default = sync.OnceValue(func() *List {
+	l, err := Parse(strings.NewReader(defaultList))
+	if err != nil {
+		panic(fmt.Sprintf("psl: invalid embedded list: %v", err))
+	}
+	return l
+})
+
+// A List is a parsed Public Suffix List. A List is safe for concurrent use
+// by multiple goroutines.
+type List struct {
+	rules map[string]rule // keyed by the rule name without "*." or "!"
+}
+
+// A rule records the kinds of rules defined for a single name.
+type rule struct {
+	kind    ruleKind
+	private bool // from the private domains section of the list
+}
+
+type ruleKind uint8
+
+const (
+	normalRule    ruleKind = 1 << iota // e.g., "co.uk"
+	wildcardRule                       // e.g., "*.ck"
+	exceptionRule                      // e.g., "!www.ck"
+)
+
+// Parse parses a Public Suffix List in the standard text format from r.
+// Names in Unicode are converted to their ASCII (Punycode) form.
+func Parse(r io.Reader) (*List, error) {
+	l := &List{rules: make(map[string]rule)}
+	var private bool
+	sc := bufio.NewScanner(r)
+	var lnum int
+	for sc.Scan() {
+		lnum++
+		line := strings.TrimSpace(sc.Text())
+		if strings.HasPrefix(line, "// ===BEGIN PRIVATE DOMAINS===") {
+			private = true
+			continue
+		} else if strings.HasPrefix(line, "// ===END PRIVATE DOMAINS===") {
+			private = false
+			continue
+		} else if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+		name, _, _ := strings.Cut(line, " ")
+		kind := normalRule
+		if rest, ok := strings.CutPrefix(name, "*."); ok {
+			name, kind = rest, wildcardRule
+		} else if rest, ok := strings.CutPrefix(name, "!"); ok {
+			name, kind = rest, exceptionRule
+		}
+		key, err := normalize(name)
+		if err != nil || key == "" {
+			return nil, fmt.Errorf("line %d: invalid rule %q", lnum, line)
+		}
+		r := l.rules[key]
+		r.kind |= kind
+		r.private = r.private || private
+		l.rules[key] = r
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	} else if len(l.rules) == 0 {
+		return nil, errors.New("no rules found")
+	}
+	return l, nil
+}
+
+// Len reports the number of names having rules in l.
+func (l *List) Len() int { return len(l.rules) }
+
+// PublicSuffix returns the public suffix of domain, and reports whether the
+// rule that selected it is from the ICANN section of the list, as opposed to
+// the private section. If no rule matches, the public suffix is the last
+// label of domain, as the list specifies, and icann is false.
+//
+// The domain is converted to lower case, and a leading or trailing period is
+// ignored.
+func (l *List) PublicSuffix(domain string) (suffix string, icann bool) {
+	d, err := normalize(domain)
+	if err != nil || d == "" {
+		return d, false
+	}
+	// Check each suffix of d from longest to shortest, so that the first
+	// matching rule is the longest. An exception rule is always one label
+	// longer than the wildcard rule it overrides, so it is found first.
+	for rest := d; ; {
+		parent, hasParent := "", false
+		if i := strings.IndexByte(rest, '.'); i >= 0 {
+			parent, hasParent = rest[i+1:], true
+		}
+		if r, ok := l.rules[rest]; ok {
+			if r.kind&exceptionRule != 0 {
+				return parent, !r.private
+			}
+			if r.kind&normalRule != 0 {
+				return rest, !r.private
+			}
+		}
+		if hasParent {
+			if r, ok := l.rules[parent]; ok && r.kind&wildcardRule != 0 {
+				return rest, !r.private
+			}
+		} else {
+			return rest, false // the implicit "*" rule
+		}
+		rest = parent
+	}
+}
+
+// IsPublicSuffix reports whether domain is itself a public suffix.
+func (l *List) IsPublicSuffix(domain string) bool {
+	d, err := normalize(domain)
+	if err != nil || d == "" {
+		return false
+	}
+	ps, _ := l.PublicSuffix(d)
+	return ps == d
+}
+
+// Registrable returns the registrable domain (eTLD+1) of domain, which is its
+// public suffix plus the label before it. It reports an error if domain is
+// an IP address, or is itself a public suffix.
+func (l *List) Registrable(domain string) (string, error) {
+	d, err := normalize(domain)
+	if err != nil {
+		return "", err
+	} else if d == "" {
+		return "", errors.New("empty domain")
+	} else if net.ParseIP(d) != nil {
+		return "", fmt.Errorf("%q is an IP address", d)
+	}
+	ps, _ := l.PublicSuffix(d)
+	if ps == d {
+		return "", fmt.Errorf("%q is a public suffix", d)
+	}
+	head := strings.TrimSuffix(d, "."+ps)
+	if i := strings.LastIndexByte(head, '.'); i >= 0 {
+		head = head[i+1:]
+	}
+	return head + "." + ps, nil
+}
+
+// Site returns the registrable domain of domain as [List.Registrable], or
+// domain itself in lower case if it has no registrable domain.
+func (l *List) Site(domain string) string {
+	if site, err := l.Registrable(domain); err == nil {
+		return site
+	}
+	d, _ := normalize(domain)
+	return d
+}
+
+// normalize converts domain to lower case, removes a leading or trailing
+// period, and converts any Unicode labels to Punycode.
+func normalize(domain string) (string, error) {
+	d := strings.ToLower(strings.TrimSuffix(strings.TrimPrefix(domain, "."), "."))
+	if !hasNonASCII(d) {
+		return d, nil
+	}
+	labels := strings.Split(d, ".")
+	for i, label := range labels {
+		if hasNonASCII(label) {
+			enc, err := encodePunycode(label)
+			if err != nil {
+				return "", err
+			}
+			labels[i] = "xn--" + enc
+		}
+	}
+	return strings.Join(labels, "."), nil
+}
+
+func hasNonASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return true
+		}
+	}
+	return false
+}
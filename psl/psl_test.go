@@ -0,0 +1,115 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package psl_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/creachadair/cookies/psl"
+)
+
+func TestDefault(t *testing.T) {
+	l := psl.Default()
+	if l.Len() < 1000 {
+		t.Fatalf("Default list has %d rules, want many more", l.Len())
+	}
+
+	tests := []struct {
+		domain, suffix string
+		icann          bool
+		site           string // "" means none
+	}{
+		{"com", "com", true, ""},
+		{"example.com", "com", true, "example.com"},
+		{"WWW.Example.COM.", "com", true, "example.com"},
+		{".www.example.com", "com", true, "example.com"},
+		{"co.uk", "co.uk", true, ""},
+		{"bbc.co.uk", "co.uk", true, "bbc.co.uk"},
+		{"www.bbc.co.uk", "co.uk", true, "bbc.co.uk"},
+		{"foo.github.io", "github.io", false, "foo.github.io"},
+		{"example.unknowntld", "unknowntld", false, "example.unknowntld"},
+
+		// A suffix added to the list after 2023, to catch a stale copy.
+		{"main--site--org.aem.live", "aem.live", false, "main--site--org.aem.live"},
+
+		// Wildcard and exception rules: *.ck, !www.ck
+		{"foo.ck", "foo.ck", true, ""},
+		{"bar.foo.ck", "foo.ck", true, "bar.foo.ck"},
+		{"www.ck", "ck", true, "www.ck"},
+		{"a.www.ck", "ck", true, "www.ck"},
+	}
+	for _, tc := range tests {
+		suffix, icann := l.PublicSuffix(tc.domain)
+		if suffix != tc.suffix || icann != tc.icann {
+			t.Errorf("PublicSuffix(%q): got (%q, %v), want (%q, %v)", tc.domain, suffix, icann, tc.suffix, tc.icann)
+		}
+		site, err := l.Registrable(tc.domain)
+		if tc.site == "" {
+			if err == nil {
+				t.Errorf("Registrable(%q): got %q, want error", tc.domain, site)
+			}
+			if !l.IsPublicSuffix(tc.domain) {
+				t.Errorf("IsPublicSuffix(%q): got false, want true", tc.domain)
+			}
+		} else if err != nil {
+			t.Errorf("Registrable(%q): unexpected error: %v", tc.domain, err)
+		} else if site != tc.site {
+			t.Errorf("Registrable(%q): got %q, want %q", tc.domain, site, tc.site)
+		}
+	}
+
+	if _, err := l.Registrable("127.0.0.1"); err == nil {
+		t.Error("Registrable(127.0.0.1): got nil, want error")
+	}
+	if got := l.Site("192.168.0.1"); got != "192.168.0.1" {
+		t.Errorf("Site(192.168.0.1): got %q, want unchanged", got)
+	}
+}
+
+func TestParse(t *testing.T) {
+	l, err := psl.Parse(strings.NewReader(`// A comment
+// ===BEGIN ICANN DOMAINS===
+cn
+公司.cn
+bücher.example
+// ===END ICANN DOMAINS===
+// ===BEGIN PRIVATE DOMAINS===
+*.private.cn
+// ===END PRIVATE DOMAINS===
+`))
+	if err != nil {
+		t.Fatalf("Parse: unexpected error: %v", err)
+	}
+	tests := []struct {
+		domain, suffix string
+		icann          bool
+	}{
+		{"www.xn--55qx5d.cn", "xn--55qx5d.cn", true},
+		{"www.公司.cn", "xn--55qx5d.cn", true},
+		{"a.xn--bcher-kva.example", "xn--bcher-kva.example", true},
+		{"a.b.private.cn", "b.private.cn", false},
+	}
+	for _, tc := range tests {
+		suffix, icann := l.PublicSuffix(tc.domain)
+		if suffix != tc.suffix || icann != tc.icann {
+			t.Errorf("PublicSuffix(%q): got (%q, %v), want (%q, %v)", tc.domain, suffix, icann, tc.suffix, tc.icann)
+		}
+	}
+
+	if _, err := psl.Parse(strings.NewReader("// nothing here\n")); err == nil {
+		t.Error("Parse empty list: got nil, want error")
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cookies
+
+import (
+	"strings"
+	"time"
+)
+
+// A Query selects cookies by simple criteria. Each non-zero field adds a
+// condition, and a cookie matches the query if it satisfies all of them.
+// The zero Query matches every cookie.
+type Query struct {
+	// If non-empty, select cookies whose domain is equal to this domain or is
+	// a subdomain of it. Comparison is case-insensitive, and a leading period
+	// on either side is ignored, so "example.com" matches ".example.com" and
+	// "www.example.com" but not "badexample.com".
+	Domain string
+
+	// If non-empty, select cookies with exactly this name.
+	Name string
+
+	// If non-zero, select cookies that expire strictly before this time.
+	// Cookies with no expiration are not selected.
+	ExpiresBefore time.Time
+
+	// If non-zero, select cookies that expire at or after this time.
+	// Cookies with no expiration are selected.
+	ExpiresAfter time.Time
+}
+
+// Match reports whether c satisfies all the conditions of q.
+func (q Query) Match(c C) bool {
+	if q.Domain != "" && !DomainWithin(c.Domain, q.Domain) {
+		return false
+	}
+	if q.Name != "" && c.Name != q.Name {
+		return false
+	}
+	if !q.ExpiresBefore.IsZero() && (c.Expires.IsZero() || !c.Expires.Before(q.ExpiresBefore)) {
+		return false
+	}
+	if !q.ExpiresAfter.IsZero() && !c.Expires.IsZero() && c.Expires.Before(q.ExpiresAfter) {
+		return false
+	}
+	return true
+}
+
+// DomainWithin reports whether domain is equal to or a subdomain of parent.
+// Comparison is case-insensitive, and a leading period on either argument is
+// ignored.
+func DomainWithin(domain, parent string) bool {
+	d := strings.ToLower(strings.TrimPrefix(domain, "."))
+	p := strings.ToLower(strings.TrimPrefix(parent, "."))
+	return d == p || strings.HasSuffix(d, "."+p)
+}
+
+// A FilteredStore is a Store that can select the cookies matching a Query
+// more efficiently than by scanning all of them, for example by translating
+// the query into a database search.
+type FilteredStore interface {
+	Store
+
+	// ScanWhere behaves as Scan, but calls f only for cookies that match q.
+	// Cookies that do not match q are retained unmodified.
+	ScanWhere(q Query, f ScanFunc) error
+}
+
+// ScanWhere calls f for each cookie in s that matches q, with the semantics
+// of the Scan method of a [Store]. Cookies that do not match q are retained.
+// If s implements [FilteredStore], its ScanWhere method is used; otherwise
+// ScanWhere calls s.Scan and filters the results.
+func ScanWhere(s Store, q Query, f ScanFunc) error {
+	if fs, ok := s.(FilteredStore); ok {
+		return fs.ScanWhere(q, f)
+	}
+	return s.Scan(func(e Editor) (Action, error) {
+		if !q.Match(e.Get()) {
+			return Keep, nil
+		}
+		return f(e)
+	})
+}
@@ -0,0 +1,55 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cookies_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/creachadair/cookies"
+)
+
+func TestQueryMatch(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	c := cookies.C{Name: "sid", Domain: ".Example.com", Expires: now}
+	session := cookies.C{Name: "sid", Domain: "www.example.com"}
+
+	tests := []struct {
+		q    cookies.Query
+		c    cookies.C
+		want bool
+	}{
+		{cookies.Query{}, c, true},
+		{cookies.Query{Domain: "example.com"}, c, true},
+		{cookies.Query{Domain: ".EXAMPLE.COM"}, c, true},
+		{cookies.Query{Domain: "example.com"}, session, true},
+		{cookies.Query{Domain: "www.example.com"}, c, false},
+		{cookies.Query{Domain: "le.com"}, c, false},
+		{cookies.Query{Name: "sid"}, c, true},
+		{cookies.Query{Name: "SID"}, c, false},
+		{cookies.Query{ExpiresBefore: now.Add(time.Second)}, c, true},
+		{cookies.Query{ExpiresBefore: now}, c, false},
+		{cookies.Query{ExpiresBefore: now}, session, false},
+		{cookies.Query{ExpiresAfter: now}, c, true},
+		{cookies.Query{ExpiresAfter: now.Add(time.Second)}, c, false},
+		{cookies.Query{ExpiresAfter: now}, session, true},
+		{cookies.Query{Domain: "example.com", Name: "other"}, c, false},
+	}
+	for _, tc := range tests {
+		if got := tc.q.Match(tc.c); got != tc.want {
+			t.Errorf("%+v.Match(%+v): got %v, want %v", tc.q, tc.c, got, tc.want)
+		}
+	}
+}
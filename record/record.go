@@ -0,0 +1,187 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package record implements an HTTP proxy that records the cookies set by
+// the responses passing through it into a [cookies.KeyedStore].
+//
+// The proxy runs in one of two modes. By default it is a forward proxy for
+// plain HTTP, suitable for use with the HTTP_PROXY environment variable.
+// Since it does not intercept TLS, it rejects CONNECT requests. If a Target
+// URL is set, it is instead a reverse proxy that sends every request to the
+// target, so that clients can reach an HTTPS site through a local address:
+//
+//	p := record.New(store, &record.Options{Target: target, Log: os.Stderr})
+//	log.Fatal(http.ListenAndServe("localhost:8080", p))
+//
+// Cookies are applied as a user agent would store them: A cookie replaces any
+// existing cookie with the same key, keeping its creation time, and a cookie
+// that has already expired removes the existing cookie instead.
+package record
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/creachadair/cookies"
+)
+
+// Options are optional settings for a Proxy.
+// A nil *Options is ready for use with default settings.
+type Options struct {
+	// If set, forward all requests to this URL, as a reverse proxy. The path
+	// of each request is joined to the path of the target.
+	Target *url.URL
+
+	// The transport used to send requests. If nil, http.DefaultTransport is
+	// used.
+	Transport http.RoundTripper
+
+	// If non-nil, a line is written here describing each change to the store,
+	// and each Set-Cookie value that was skipped as invalid.
+	Log io.Writer
+}
+
+func (o *Options) target() *url.URL {
+	if o == nil {
+		return nil
+	}
+	return o.Target
+}
+
+func (o *Options) transport() http.RoundTripper {
+	if o == nil || o.Transport == nil {
+		return http.DefaultTransport
+	}
+	return o.Transport
+}
+
+func (o *Options) log() io.Writer {
+	if o == nil {
+		return nil
+	}
+	return o.Log
+}
+
+// A Proxy is an [http.Handler] that proxies requests and records the cookies
+// set by their responses. It is safe for concurrent use by multiple
+// goroutines, provided the store is not accessed by other means while the
+// proxy is in use.
+type Proxy struct {
+	target *url.URL
+	rp     *httputil.ReverseProxy
+
+	mu    sync.Mutex
+	store cookies.KeyedStore
+	log   io.Writer
+}
+
+// New returns a Proxy that records cookies into s.
+func New(s cookies.KeyedStore, opts *Options) *Proxy {
+	p := &Proxy{target: opts.target(), store: s, log: opts.log()}
+	p.rp = &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			if p.target != nil {
+				pr.SetURL(p.target)
+			}
+		},
+		Transport: opts.transport(),
+		ModifyResponse: func(rsp *http.Response) error {
+			return p.Record(rsp.Request.URL, rsp.Header)
+		},
+	}
+	return p
+}
+
+// ServeHTTP implements the [http.Handler] interface.
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if p.target == nil {
+		if r.Method == http.MethodConnect {
+			http.Error(w, "CONNECT is not supported", http.StatusNotImplemented)
+			return
+		} else if !r.URL.IsAbs() {
+			http.Error(w, "request is not for a proxy", http.StatusBadRequest)
+			return
+		}
+	}
+	p.rp.ServeHTTP(w, r)
+}
+
+// Record applies the cookies set by the Set-Cookie fields of h, received in
+// response to a request for u, to the store, and commits the store if it
+// changed. Set-Cookie values that a user agent would ignore are skipped.
+//
+// The proxy calls Record for each response. If it reports an error, the
+// client receives a 502 Bad Gateway response.
+func (p *Proxy) Record(u *url.URL, h http.Header) error {
+	lines := h.Values("Set-Cookie")
+	if len(lines) == 0 {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	var changed bool
+	for _, line := range lines {
+		c, err := cookies.ParseSetCookie(line, u, now)
+		if err != nil {
+			p.logf(now, "skip", u, "%s (%v)", line, err)
+			continue
+		}
+		old, err := p.store.Get(c.Key())
+		exists := err == nil
+		if err != nil && !errors.Is(err, cookies.ErrNotFound) {
+			return err
+		}
+
+		if !c.Expires.IsZero() && !c.Expires.After(now) {
+			if exists {
+				if err := p.store.Delete(c.Key()); err != nil {
+					return err
+				}
+				p.logf(now, "expire", u, "%s", cookies.FormatSetCookie(old))
+				changed = true
+			}
+			continue
+		}
+
+		action := "add"
+		if exists {
+			c.Created = old.Created
+			action = "replace"
+		}
+		if err := p.store.Put(c); err != nil {
+			return err
+		}
+		p.logf(now, action, u, "%s", cookies.FormatSetCookie(c))
+		changed = true
+	}
+	if changed {
+		return p.store.Commit()
+	}
+	return nil
+}
+
+// logf writes a line describing a change to the log, if there is one.
+func (p *Proxy) logf(now time.Time, action string, u *url.URL, msg string, args ...any) {
+	if p.log != nil {
+		fmt.Fprintf(p.log, "%s %s %s %s\n", now.UTC().Format(time.RFC3339), action, u, fmt.Sprintf(msg, args...))
+	}
+}
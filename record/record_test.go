@@ -0,0 +1,210 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package record_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/creachadair/cookies"
+	"github.com/creachadair/cookies/record"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+// mapStore is a minimal in-memory cookies.KeyedStore.
+type mapStore struct {
+	m       map[cookies.Key]cookies.C
+	commits int
+}
+
+func (s *mapStore) Commit() error { s.commits++; return nil }
+
+func (s *mapStore) Scan(f cookies.ScanFunc) error {
+	for _, key := range slices.Collect(maps.Keys(s.m)) {
+		e := &mapEditor{c: s.m[key]}
+		act, err := f(e)
+		if err != nil {
+			return err
+		}
+		switch act {
+		case cookies.Keep:
+		case cookies.Update:
+			delete(s.m, key)
+			s.m[e.c.Key()] = e.c
+		case cookies.Discard:
+			delete(s.m, key)
+		default:
+			return fmt.Errorf("unknown action %v", act)
+		}
+	}
+	return nil
+}
+
+// mapEditor is the cookies.Editor for a cookie in a mapStore.
+type mapEditor struct{ c cookies.C }
+
+func (e *mapEditor) Get() cookies.C { return e.c }
+
+func (e *mapEditor) Set(c cookies.C) error {
+	if err := cookies.Validate(c); err != nil {
+		return err
+	}
+	e.c = c
+	return nil
+}
+
+func (s *mapStore) Get(key cookies.Key) (cookies.C, error) {
+	if c, ok := s.m[key]; ok {
+		return c, nil
+	}
+	return cookies.C{}, cookies.ErrNotFound
+}
+
+func (s *mapStore) Put(c cookies.C) error { s.m[c.Key()] = c; return nil }
+
+func (s *mapStore) Delete(key cookies.Key) error { delete(s.m, key); return nil }
+
+func (s *mapStore) names(t *testing.T) []string {
+	t.Helper()
+	var out []string
+	for c, err := range cookies.All(s) {
+		if err != nil {
+			t.Fatalf("Listing cookies: %v", err)
+		}
+		out = append(out, c.Name+"="+c.Value)
+	}
+	slices.Sort(out)
+	return out
+}
+
+func newSite(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, v := range r.URL.Query()["set"] {
+			w.Header().Add("Set-Cookie", v)
+		}
+		io.WriteString(w, "ok "+r.URL.Path)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func get(t *testing.T, cli *http.Client, u string, set ...string) string {
+	t.Helper()
+	q := make(url.Values)
+	q["set"] = set
+	rsp, err := cli.Get(u + "?" + q.Encode())
+	if err != nil {
+		t.Fatalf("Get %q: %v", u, err)
+	}
+	defer rsp.Body.Close()
+	body, _ := io.ReadAll(rsp.Body)
+	if rsp.StatusCode != http.StatusOK {
+		t.Fatalf("Get %q: status %s", u, rsp.Status)
+	}
+	return string(body)
+}
+
+func TestForwardProxy(t *testing.T) {
+	site := newSite(t)
+	s := &mapStore{m: make(map[cookies.Key]cookies.C)}
+	var log bytes.Buffer
+	proxy := httptest.NewServer(record.New(s, &record.Options{Log: &log}))
+	defer proxy.Close()
+	pu, _ := url.Parse(proxy.URL)
+	cli := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(pu)}}
+
+	if got := get(t, cli, site.URL+"/a", "sid=1; Path=/", "pref=dark; Path=/"); got != "ok /a" {
+		t.Errorf("Response body: got %q, want ok /a", got)
+	}
+	if diff := cmp.Diff([]string{"pref=dark", "sid=1"}, s.names(t)); diff != "" {
+		t.Errorf("After first request (-want, +got):\n%s", diff)
+	}
+	su, _ := url.Parse(site.URL)
+	key := cookies.Key{Domain: su.Hostname(), Name: "sid", Path: "/"}
+	created := s.m[key].Created
+
+	// Replacing a cookie keeps its creation time, and expiring one removes it.
+	time.Sleep(5 * time.Millisecond)
+	get(t, cli, site.URL+"/b", "sid=2; Path=/", "pref=; Path=/; Max-Age=0",
+		"gone=; Path=/; Expires=Thu, 01 Jan 1970 00:00:00 GMT", "bad=1; Domain=other.com")
+	if diff := cmp.Diff([]string{"sid=2"}, s.names(t)); diff != "" {
+		t.Errorf("After second request (-want, +got):\n%s", diff)
+	}
+	if got := s.m[key].Created; !got.Equal(created) {
+		t.Errorf("Replaced cookie created %v, want %v", got, created)
+	}
+	if s.commits != 2 {
+		t.Errorf("Got %d commits, want 2", s.commits)
+	}
+
+	// No cookies set, no commit.
+	get(t, cli, site.URL+"/c")
+	if s.commits != 2 {
+		t.Errorf("Got %d commits, want 2", s.commits)
+	}
+
+	var actions []string
+	for line := range strings.Lines(log.String()) {
+		actions = append(actions, strings.Fields(line)[1])
+	}
+	if diff := cmp.Diff([]string{"add", "add", "replace", "expire", "skip"}, actions,
+		cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+		t.Errorf("Log actions (-want, +got):\n%s\nLog:\n%s", diff, log.String())
+	}
+
+	// A non-proxy request is rejected.
+	rsp, err := http.Get(proxy.URL + "/x")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	rsp.Body.Close()
+	if rsp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Direct request: got %s, want 400", rsp.Status)
+	}
+}
+
+func TestReverseProxy(t *testing.T) {
+	site := newSite(t)
+	target, err := url.Parse(site.URL + "/base")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	s := &mapStore{m: make(map[cookies.Key]cookies.C)}
+	proxy := httptest.NewServer(record.New(s, &record.Options{Target: target}))
+	defer proxy.Close()
+
+	if got := get(t, http.DefaultClient, proxy.URL+"/page", "sid=1"); got != "ok /base/page" {
+		t.Errorf("Response body: got %q, want ok /base/page", got)
+	}
+
+	// The cookie is recorded for the target, with its default path.
+	got := slices.Collect(maps.Values(s.m))
+	want := []cookies.C{{Name: "sid", Value: "1", Domain: target.Hostname(), Path: "/base",
+		Flags: cookies.Flags{HostOnly: true}}}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(cookies.C{}, "Created")); diff != "" {
+		t.Errorf("Recorded cookies (-want, +got):\n%s", diff)
+	}
+}
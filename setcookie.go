@@ -0,0 +1,351 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cookies
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/creachadair/cookies/psl"
+)
+
+// maxCookieAge is the longest lifetime a user agent grants a cookie, as
+// specified by RFC 6265bis section 5.6.1.
+const maxCookieAge = 400 * 24 * time.Hour
+
+// httpTimeFormat is the preferred format for dates in HTTP headers, as
+// defined by RFC 9110 section 5.6.7.
+const httpTimeFormat = "Mon, 02 Jan 2006 15:04:05 GMT"
+
+// ParseSetCookie parses the value of a Set-Cookie response header, following
+// the algorithms of RFC 6265bis sections 5.6 and 5.7, and returns the cookie
+// a user agent would store. The reqURL is the URL of the request whose
+// response included the header, and now is the time the response was
+// received, which is recorded as the creation time of the cookie.
+//
+// The Domain attribute of the header, if any, must domain-match the host of
+// reqURL, and must not be a public suffix other than the host itself;
+// otherwise the cookie is host-only for that host. If the Path attribute is
+// absent or does not begin with "/", the default path of reqURL is used.
+//
+// Expiration is computed from the Max-Age attribute if present, otherwise
+// from the Expires attribute, and is limited to 400 days after now. A cookie
+// with a non-positive Max-Age has an Expires time of the Unix epoch, in the
+// past; one with neither attribute is a session cookie, with zero Expires.
+//
+// ParseSetCookie reports an error wrapping [ErrInvalid] if a user agent would
+// ignore the header, for example because the result is not valid (see
+// [Validate]), because a Secure cookie was set over an insecure scheme, or
+// because a "__Secure-" or "__Host-" name prefix is not satisfied.
+// Attributes that are not understood, or have invalid values, are ignored.
+func ParseSetCookie(line string, reqURL *url.URL, now time.Time) (C, error) {
+	if i := strings.IndexFunc(line, func(r rune) bool { return isControl(r) && r != '\t' }); i >= 0 {
+		return C{}, invalid("header contains %q", line[i])
+	}
+	host := strings.ToLower(reqURL.Hostname())
+	if host == "" {
+		return C{}, invalid("request URL %q has no host", reqURL)
+	}
+	secureScheme := reqURL.Scheme == "https" || reqURL.Scheme == "wss"
+
+	nv, attrs, _ := strings.Cut(line, ";")
+	name, value, ok := strings.Cut(nv, "=")
+	if !ok {
+		name, value = "", name // a nameless cookie
+	}
+	c := C{
+		Name:    trimSpace(name),
+		Value:   trimSpace(value),
+		Created: now,
+	}
+
+	var domain, path string
+	var maxAge time.Time
+	var hasMaxAge bool
+	for attrs != "" {
+		var av string
+		av, attrs, _ = strings.Cut(attrs, ";")
+		key, val, _ := strings.Cut(av, "=")
+		key, val = trimSpace(key), trimSpace(val)
+		if len(val) > maxAttrSize {
+			continue
+		}
+		switch strings.ToLower(key) {
+		case "expires":
+			if t, ok := parseCookieDate(val); ok {
+				c.Expires = t
+				if limit := now.Add(maxCookieAge); t.After(limit) {
+					c.Expires = limit
+				}
+			}
+		case "max-age":
+			if t, ok := parseMaxAge(val, now); ok {
+				maxAge, hasMaxAge = t, true
+			}
+		case "domain":
+			if val != "" {
+				domain = strings.ToLower(strings.TrimPrefix(val, "."))
+			}
+		case "path":
+			path = val
+		case "secure":
+			c.Flags.Secure = true
+		case "httponly":
+			c.Flags.HTTPOnly = true
+		case "samesite":
+			switch strings.ToLower(val) {
+			case "none":
+				c.SameSite = None
+			case "strict":
+				c.SameSite = Strict
+			case "lax":
+				c.SameSite = Lax
+			default:
+				c.SameSite = Unknown
+			}
+		case "partitioned":
+			c.Flags.Partitioned = true
+		}
+	}
+	if hasMaxAge {
+		c.Expires = maxAge
+	}
+
+	if domain != "" && psl.Default().IsPublicSuffix(domain) {
+		if domain != host {
+			return C{}, invalid("domain %q is a public suffix", domain)
+		}
+		domain = ""
+	}
+	if domain == "" {
+		c.Domain, c.Flags.HostOnly = host, true
+	} else if !domainMatch(C{Domain: domain}, host) {
+		return C{}, invalid("domain %q does not match host %q", domain, host)
+	} else {
+		c.Domain = domain
+	}
+
+	if path == "" || !strings.HasPrefix(path, "/") {
+		path = defaultPath(reqURL)
+	}
+	c.Path = path
+
+	if c.Flags.Secure && !secureScheme {
+		return C{}, invalid("secure cookie %q set by %q", c.Name, reqURL.Scheme)
+	} else if c.Flags.Partitioned && !c.Flags.Secure {
+		return C{}, invalid("partitioned cookie %q is not secure", c.Name)
+	}
+	if err := checkPrefix(c); err != nil {
+		return C{}, err
+	}
+	if err := Validate(c); err != nil {
+		return C{}, err
+	}
+	return c, nil
+}
+
+// FormatSetCookie returns the value of a Set-Cookie response header that
+// would cause a user agent to store c. The result omits the creation time of
+// c, and gives its expiration time to the nearest second.
+//
+// A domain cookie is rendered with a Domain attribute, and a host-only cookie
+// without one. If c has no path, the Path attribute is omitted, and a user
+// agent will use the default path of the request instead.
+func FormatSetCookie(c C) string {
+	var sb strings.Builder
+	sb.WriteString(c.Name)
+	sb.WriteByte('=')
+	sb.WriteString(c.Value)
+	if !c.Flags.HostOnly {
+		fmt.Fprintf(&sb, "; Domain=%s", strings.TrimPrefix(c.Domain, "."))
+	}
+	if c.Path != "" {
+		fmt.Fprintf(&sb, "; Path=%s", c.Path)
+	}
+	if !c.Expires.IsZero() {
+		fmt.Fprintf(&sb, "; Expires=%s", c.Expires.UTC().Format(httpTimeFormat))
+	}
+	if c.Flags.Secure {
+		sb.WriteString("; Secure")
+	}
+	if c.Flags.HTTPOnly {
+		sb.WriteString("; HttpOnly")
+	}
+	if c.SameSite != Unknown {
+		fmt.Fprintf(&sb, "; SameSite=%s", c.SameSite)
+	}
+	if c.Flags.Partitioned {
+		sb.WriteString("; Partitioned")
+	}
+	return sb.String()
+}
+
+// trimSpace removes leading and trailing spaces and tabs from s.
+func trimSpace(s string) string { return strings.Trim(s, " \t") }
+
+// defaultPath returns the default path for a cookie set in response to a
+// request for u, as defined by RFC 6265 section 5.1.4.
+func defaultPath(u *url.URL) string {
+	p := u.EscapedPath()
+	if !strings.HasPrefix(p, "/") {
+		return "/"
+	}
+	if i := strings.LastIndex(p, "/"); i > 0 {
+		return p[:i]
+	}
+	return "/"
+}
+
+// checkPrefix reports an error if the name of c has a "__Secure-" or
+// "__Host-" prefix whose requirements c does not satisfy, as defined by
+// RFC 6265bis section 4.1.3.
+func checkPrefix(c C) error {
+	name := strings.ToLower(c.Name)
+	if strings.HasPrefix(name, "__secure-") && !c.Flags.Secure {
+		return invalid("cookie %q is not secure", c.Name)
+	}
+	if strings.HasPrefix(name, "__host-") &&
+		(!c.Flags.Secure || !c.Flags.HostOnly || c.Path != "/") {
+		return invalid("cookie %q must be secure and host-only, with path /", c.Name)
+	}
+	return nil
+}
+
+// parseMaxAge parses the value of a Max-Age attribute received at now, as
+// defined by RFC 6265bis section 5.6.2, and returns the resulting expiration
+// time.
+func parseMaxAge(s string, now time.Time) (time.Time, bool) {
+	digits := strings.TrimPrefix(s, "-")
+	if digits == "" || strings.IndexFunc(digits, notDigit) >= 0 {
+		return time.Time{}, false
+	}
+	secs, err := strconv.ParseInt(s, 10, 64)
+	if (err != nil && digits != s) || (err == nil && secs <= 0) {
+		return time.Unix(0, 0).UTC(), true
+	}
+	maxSecs := int64(maxCookieAge / time.Second)
+	if err != nil || secs > maxSecs {
+		secs = maxSecs // including overflow
+	}
+	return now.Add(time.Duration(secs) * time.Second), true
+}
+
+// parseCookieDate parses s as a date using the algorithm of RFC 6265
+// section 5.1.1, which accepts the many date formats used by servers.
+func parseCookieDate(s string) (time.Time, bool) {
+	var (
+		hour, minute, sec int
+		day, month, year  int
+		foundTime         bool
+		foundDay          bool
+		foundMonth        bool
+		foundYear         bool
+	)
+	for _, tok := range strings.FieldsFunc(s, isDateDelimiter) {
+		if !foundTime {
+			if h, m, s, ok := parseCookieTime(tok); ok {
+				hour, minute, sec, foundTime = h, m, s, true
+				continue
+			}
+		}
+		if !foundDay {
+			if v, ok := leadingDigits(tok, 1, 2); ok {
+				day, foundDay = v, true
+				continue
+			}
+		}
+		if !foundMonth && len(tok) >= 3 {
+			if i := strings.Index(monthNames, strings.ToLower(tok[:3])); i >= 0 && i%3 == 0 {
+				month, foundMonth = i/3+1, true
+				continue
+			}
+		}
+		if !foundYear {
+			if v, ok := leadingDigits(tok, 2, 4); ok {
+				year, foundYear = v, true
+				continue
+			}
+		}
+	}
+	if year >= 70 && year <= 99 {
+		year += 1900
+	} else if year >= 0 && year <= 69 {
+		year += 2000
+	}
+	if !foundTime || !foundDay || !foundMonth || !foundYear ||
+		day < 1 || day > 31 || year < 1601 || hour > 23 || minute > 59 || sec > 59 {
+		return time.Time{}, false
+	}
+	t := time.Date(year, time.Month(month), day, hour, minute, sec, 0, time.UTC)
+	if t.Day() != day {
+		return time.Time{}, false // e.g., February 30
+	}
+	return t, true
+}
+
+const monthNames = "janfebmaraprmayjunjulaugsepoctnovdec"
+
+// parseCookieTime parses a time token of the form hh:mm:ss, in which each
+// field has one or two digits, optionally followed by non-digits.
+func parseCookieTime(tok string) (h, m, s int, ok bool) {
+	var fields [3]int
+	for i := range fields {
+		n := 0
+		for n < len(tok) && n < 2 && !notDigit(rune(tok[n])) {
+			n++
+		}
+		if n == 0 {
+			return 0, 0, 0, false
+		}
+		fields[i], _ = strconv.Atoi(tok[:n])
+		tok = tok[n:]
+		if i < 2 {
+			if !strings.HasPrefix(tok, ":") {
+				return 0, 0, 0, false
+			}
+			tok = tok[1:]
+		}
+	}
+	if tok != "" && !notDigit(rune(tok[0])) {
+		return 0, 0, 0, false
+	}
+	return fields[0], fields[1], fields[2], true
+}
+
+// leadingDigits parses a token consisting of between lo and hi digits,
+// optionally followed by non-digits.
+func leadingDigits(tok string, lo, hi int) (int, bool) {
+	n := 0
+	for n < len(tok) && !notDigit(rune(tok[n])) {
+		n++
+	}
+	if n < lo || n > hi {
+		return 0, false
+	}
+	v, _ := strconv.Atoi(tok[:n])
+	return v, true
+}
+
+func notDigit(r rune) bool { return r < '0' || r > '9' }
+
+// isDateDelimiter reports whether r is a delimiter in a cookie date, as
+// defined by RFC 6265 section 5.1.1.
+func isDateDelimiter(r rune) bool {
+	return r == '\t' || (r >= 0x20 && r <= 0x2f) || (r >= 0x3b && r <= 0x40) ||
+		(r >= 0x5b && r <= 0x60) || (r >= 0x7b && r <= 0x7e)
+}
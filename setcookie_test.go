@@ -0,0 +1,197 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cookies_test
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/creachadair/cookies"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseSetCookie(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		line, url string
+		want      cookies.C
+	}{
+		{"sid=abc", "http://www.example.com/docs/index.html", cookies.C{
+			Name: "sid", Value: "abc", Domain: "www.example.com", Path: "/docs",
+			Flags: cookies.Flags{HostOnly: true},
+		}},
+		{" sid = abc ; Path=/ ; Domain=.Example.COM", "http://www.example.com/", cookies.C{
+			Name: "sid", Value: "abc", Domain: "example.com", Path: "/",
+		}},
+		{"a=1; Path=relative", "http://example.com/x", cookies.C{
+			Name: "a", Value: "1", Domain: "example.com", Path: "/",
+			Flags: cookies.Flags{HostOnly: true},
+		}},
+		{"a=1; Secure; HttpOnly; SameSite=strict; Partitioned", "https://example.com/", cookies.C{
+			Name: "a", Value: "1", Domain: "example.com", Path: "/", SameSite: cookies.Strict,
+			Flags: cookies.Flags{HostOnly: true, Secure: true, HTTPOnly: true, Partitioned: true},
+		}},
+		{"a=1; SameSite=bogus", "http://example.com/", cookies.C{
+			Name: "a", Value: "1", Domain: "example.com", Path: "/",
+			Flags: cookies.Flags{HostOnly: true},
+		}},
+		{"a=1; Expires=Wed, 09 Jun 2027 10:18:14 GMT", "http://example.com/", cookies.C{
+			Name: "a", Value: "1", Domain: "example.com", Path: "/",
+			Expires: time.Date(2027, 6, 9, 10, 18, 14, 0, time.UTC),
+			Flags:   cookies.Flags{HostOnly: true},
+		}},
+		{"a=1; expires=Wednesday, 09-Jun-26 10:18:14 GMT", "http://example.com/", cookies.C{
+			Name: "a", Value: "1", Domain: "example.com", Path: "/",
+			Expires: time.Date(2026, 6, 9, 10, 18, 14, 0, time.UTC),
+			Flags:   cookies.Flags{HostOnly: true},
+		}},
+		{"a=1; Expires=Sun Nov  6 08:49:37 1994", "http://example.com/", cookies.C{
+			Name: "a", Value: "1", Domain: "example.com", Path: "/",
+			Expires: time.Date(1994, 11, 6, 8, 49, 37, 0, time.UTC),
+			Flags:   cookies.Flags{HostOnly: true},
+		}},
+		{"a=1; Expires=Feb 30 2027 00:00:00", "http://example.com/", cookies.C{
+			Name: "a", Value: "1", Domain: "example.com", Path: "/",
+			Flags: cookies.Flags{HostOnly: true},
+		}},
+		{"a=1; Max-Age=3600; Expires=Wed, 09 Jun 2027 10:18:14 GMT", "http://example.com/", cookies.C{
+			Name: "a", Value: "1", Domain: "example.com", Path: "/",
+			Expires: now.Add(time.Hour),
+			Flags:   cookies.Flags{HostOnly: true},
+		}},
+		{"a=1; Max-Age=0", "http://example.com/", cookies.C{
+			Name: "a", Value: "1", Domain: "example.com", Path: "/",
+			Expires: time.Unix(0, 0).UTC(),
+			Flags:   cookies.Flags{HostOnly: true},
+		}},
+		{"a=1; Max-Age=1x", "http://example.com/", cookies.C{
+			Name: "a", Value: "1", Domain: "example.com", Path: "/",
+			Flags: cookies.Flags{HostOnly: true},
+		}},
+		{"a=1; Max-Age=999999999999999999999", "http://example.com/", cookies.C{
+			Name: "a", Value: "1", Domain: "example.com", Path: "/",
+			Expires: now.Add(400 * 24 * time.Hour),
+			Flags:   cookies.Flags{HostOnly: true},
+		}},
+		{"a=1; Expires=Fri, 01 Jan 2100 00:00:00 GMT", "http://example.com/", cookies.C{
+			Name: "a", Value: "1", Domain: "example.com", Path: "/",
+			Expires: now.Add(400 * 24 * time.Hour),
+			Flags:   cookies.Flags{HostOnly: true},
+		}},
+		// A public suffix naming the request host yields a host-only cookie.
+		{"a=1; Domain=localhost", "http://localhost/", cookies.C{
+			Name: "a", Value: "1", Domain: "localhost", Path: "/",
+			Flags: cookies.Flags{HostOnly: true},
+		}},
+		// Nameless cookies, as defined by RFC 6265bis.
+		{"novalue; Path=/", "http://example.com/", cookies.C{
+			Value: "novalue", Domain: "example.com", Path: "/",
+			Flags: cookies.Flags{HostOnly: true},
+		}},
+		{"=a=b", "http://example.com/", cookies.C{
+			Value: "a=b", Domain: "example.com", Path: "/",
+			Flags: cookies.Flags{HostOnly: true},
+		}},
+		{"__Host-a=1; Secure; Path=/", "https://example.com/x/y", cookies.C{
+			Name: "__Host-a", Value: "1", Domain: "example.com", Path: "/",
+			Flags: cookies.Flags{HostOnly: true, Secure: true},
+		}},
+	}
+	for _, tc := range tests {
+		u, err := url.Parse(tc.url)
+		if err != nil {
+			t.Fatalf("Parse %q: %v", tc.url, err)
+		}
+		got, err := cookies.ParseSetCookie(tc.line, u, now)
+		if err != nil {
+			t.Errorf("ParseSetCookie(%q): unexpected error: %v", tc.line, err)
+			continue
+		}
+		tc.want.Created = now
+		if diff := cmp.Diff(tc.want, got); diff != "" {
+			t.Errorf("ParseSetCookie(%q) (-want, +got):\n%s", tc.line, diff)
+		}
+	}
+}
+
+func TestParseSetCookieInvalid(t *testing.T) {
+	tests := []struct {
+		line, url string
+	}{
+		{"", "http://example.com/"},
+		{"=", "http://example.com/"},
+		{" = ; Path=/", "http://example.com/"},
+		{"a=1\x00", "http://example.com/"},
+		{"a=1; Domain=other.com", "http://example.com/"},
+		{"a=1; Domain=co.uk", "http://example.co.uk/"},
+		{"a=1; Domain=www.example.com", "http://example.com/"},
+		{"a=1; Secure", "http://example.com/"},
+		{"a=1; Partitioned", "http://example.com/"},
+		{"__Secure-a=1", "https://example.com/"},
+		{"__Host-a=1; Secure; Domain=example.com", "https://example.com/"},
+		{"__Host-a=1; Secure; Path=/x", "https://example.com/"},
+		{"a=1", "file:///tmp/x"},
+	}
+	for _, tc := range tests {
+		u, err := url.Parse(tc.url)
+		if err != nil {
+			t.Fatalf("Parse %q: %v", tc.url, err)
+		}
+		got, err := cookies.ParseSetCookie(tc.line, u, time.Now())
+		if !errors.Is(err, cookies.ErrInvalid) {
+			t.Errorf("ParseSetCookie(%q, %q): got %+v, %v; want %v", tc.line, tc.url, got, err, cookies.ErrInvalid)
+		}
+	}
+}
+
+func TestFormatSetCookie(t *testing.T) {
+	tests := []struct {
+		c    cookies.C
+		want string
+	}{
+		{cookies.C{Name: "a", Value: "1", Domain: "example.com", Flags: cookies.Flags{HostOnly: true}},
+			"a=1"},
+		{cookies.C{Name: "sid", Value: "abc", Domain: ".example.com", Path: "/",
+			Expires:  time.Date(2027, 6, 9, 10, 18, 14, 5e8, time.FixedZone("X", 3600)),
+			Flags:    cookies.Flags{Secure: true, HTTPOnly: true, Partitioned: true},
+			SameSite: cookies.None},
+			"sid=abc; Domain=example.com; Path=/; Expires=Wed, 09 Jun 2027 09:18:14 GMT; " +
+				"Secure; HttpOnly; SameSite=None; Partitioned"},
+	}
+	for _, tc := range tests {
+		if got := cookies.FormatSetCookie(tc.c); got != tc.want {
+			t.Errorf("FormatSetCookie(%+v):\ngot  %q\nwant %q", tc.c, got, tc.want)
+		}
+	}
+
+	// Formatting and parsing a cookie round-trips.
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	c := cookies.C{
+		Name: "sid", Value: "abc", Domain: "example.com", Path: "/app",
+		Expires: now.Add(time.Hour), Created: now,
+		Flags:    cookies.Flags{Secure: true},
+		SameSite: cookies.Lax,
+	}
+	u := &url.URL{Scheme: "https", Host: "www.example.com", Path: "/app/page"}
+	got, err := cookies.ParseSetCookie(cookies.FormatSetCookie(c), u, now)
+	if err != nil {
+		t.Fatalf("ParseSetCookie: unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(c, got); diff != "" {
+		t.Errorf("Round trip (-want, +got):\n%s", diff)
+	}
+}
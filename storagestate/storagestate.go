@@ -0,0 +1,306 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package storagestate supports reading and modifying the cookies in a
+// storageState JSON file, as saved and loaded by Playwright and Puppeteer to
+// preserve browser sessions.
+//
+// A storage state file is a JSON object with a "cookies" array and an
+// "origins" array holding local storage. This package reads and writes the
+// cookies; other contents of the file are preserved unmodified.
+//
+// To copy the cookies of a browser profile into a new storage state file:
+//
+//	s := storagestate.New("state.json")
+//	for c, err := range cookies.All(src) {
+//		if err != nil {
+//			log.Fatalf("Reading cookies: %v", err)
+//		}
+//		if err := s.Put(c); err != nil {
+//			log.Fatalf("Adding cookie: %v", err)
+//		}
+//	}
+//	if err := s.Commit(); err != nil {
+//		log.Fatalf("Writing state: %v", err)
+//	}
+package storagestate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"math"
+	"os"
+	"time"
+
+	"github.com/creachadair/cookies"
+	"github.com/creachadair/cookies/internal/filestore"
+)
+
+// State is the contents of a storage state file.
+type State struct {
+	Cookies []*Cookie
+
+	// Other fields of the file, such as "origins", keyed by name.
+	Other map[string]json.RawMessage
+}
+
+// Parse parses the contents of a storage state file.
+func Parse(data []byte) (*State, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	st := &State{Other: fields}
+	if raw, ok := fields["cookies"]; ok {
+		if err := json.Unmarshal(raw, &st.Cookies); err != nil {
+			return nil, fmt.Errorf("invalid cookies: %w", err)
+		}
+		for i, c := range st.Cookies {
+			if c == nil {
+				return nil, fmt.Errorf("invalid cookies: element %d is null", i)
+			}
+		}
+		delete(fields, "cookies")
+	}
+	return st, nil
+}
+
+// WriteTo encodes st as JSON to w. If st has no "origins" field, an empty
+// one is written, since Playwright requires it.
+func (st *State) WriteTo(w io.Writer) (int64, error) {
+	fields := map[string]any{"origins": []any{}}
+	for name, raw := range st.Other {
+		fields[name] = raw
+	}
+	cs := st.Cookies
+	if cs == nil {
+		cs = []*Cookie{}
+	}
+	fields["cookies"] = cs
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(fields); err != nil {
+		return 0, err
+	}
+	return buf.WriteTo(w)
+}
+
+// A Cookie is a single cookie in the storage state format.
+type Cookie struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Domain string `json:"domain"` // with a leading period for domain cookies
+	Path   string `json:"path"`
+
+	// The expiration time in seconds since the Unix epoch, or -1 for a
+	// session cookie.
+	Expires float64 `json:"expires"`
+
+	HTTPOnly bool   `json:"httpOnly"`
+	Secure   bool   `json:"secure"`
+	SameSite string `json:"sameSite,omitempty"` // "Strict", "Lax", or "None"
+
+	// Other fields of the cookie object, such as "partitionKey", keyed by
+	// name.
+	Other map[string]json.RawMessage `json:"-"`
+}
+
+// knownFields are the JSON field names decoded into the fields of a Cookie.
+var knownFields = []string{
+	"name", "value", "domain", "path", "expires", "httpOnly", "secure", "sameSite",
+}
+
+// UnmarshalJSON implements the [json.Unmarshaler] interface.
+func (c *Cookie) UnmarshalJSON(data []byte) error {
+	type plain Cookie
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	var other map[string]json.RawMessage
+	if err := json.Unmarshal(data, &other); err != nil {
+		return err
+	}
+	for _, name := range knownFields {
+		delete(other, name)
+	}
+	if len(other) == 0 {
+		other = nil
+	}
+	*c = Cookie(p)
+	c.Other = other
+	return nil
+}
+
+// MarshalJSON implements the [json.Marshaler] interface.
+func (c *Cookie) MarshalJSON() ([]byte, error) {
+	type plain Cookie
+	data, err := json.Marshal((*plain)(c))
+	if err != nil || len(c.Other) == 0 {
+		return data, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for name, v := range c.Other {
+		if _, ok := fields[name]; !ok {
+			fields[name] = v
+		}
+	}
+	return json.Marshal(fields)
+}
+
+// Get returns a format-independent representation of c.
+// It satisfies part of [cookies.Editor].
+func (c *Cookie) Get() cookies.C {
+	domain, hostOnly := cookies.ParseHostKey(c.Domain)
+	return cookies.C{
+		Name:    c.Name,
+		Value:   c.Value,
+		Domain:  domain,
+		Path:    c.Path,
+		Expires: decodeExpires(c.Expires),
+		Flags: cookies.Flags{
+			Secure:   c.Secure,
+			HTTPOnly: c.HTTPOnly,
+			HostOnly: hostOnly,
+		},
+		SameSite: decodeSitePolicy(c.SameSite),
+	}
+}
+
+// Set updates c to match the contents of o. The format does not record the
+// creation time of a cookie, so o.Created is discarded. An Unknown SameSite
+// policy is stored as "Lax", the default applied by browsers. Other fields of
+// c are kept.
+// It satisfies part of [cookies.Editor].
+func (c *Cookie) Set(o cookies.C) error {
+	if err := cookies.Validate(o); err != nil {
+		return err
+	}
+	c.Name = o.Name
+	c.Value = o.Value
+	c.Domain = o.HostKey()
+	c.Path = o.Path
+	c.Expires = encodeExpires(o.Expires)
+	c.HTTPOnly = o.Flags.HTTPOnly
+	c.Secure = o.Flags.Secure
+	c.SameSite = encodeSitePolicy(o.SameSite)
+	return nil
+}
+
+func decodeExpires(v float64) time.Time {
+	if v < 0 {
+		return time.Time{}
+	}
+	sec, frac := math.Modf(v)
+	return time.Unix(int64(sec), int64(frac*1e9)).UTC()
+}
+
+func encodeExpires(t time.Time) float64 {
+	if t.IsZero() {
+		return -1
+	}
+	return float64(t.UnixNano()) / 1e9
+}
+
+func decodeSitePolicy(s string) cookies.SameSite {
+	switch s {
+	case "Strict":
+		return cookies.Strict
+	case "Lax":
+		return cookies.Lax
+	case "None":
+		return cookies.None
+	default:
+		return cookies.Unknown
+	}
+}
+
+func encodeSitePolicy(p cookies.SameSite) string {
+	switch p {
+	case cookies.Strict:
+		return "Strict"
+	case cookies.None:
+		return "None"
+	default:
+		return "Lax"
+	}
+}
+
+// Open opens a storage state file and returns a Store containing its data.
+func Open(path string) (*Store, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	st, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	s := &Store{path: path, other: st.Other}
+	s.list.Cookies = st.Cookies
+	return s, nil
+}
+
+// New returns an empty Store that will be written to path by Commit.
+func New(path string) *Store {
+	s := &Store{path: path}
+	s.list.Dirty = true
+	return s
+}
+
+// A Store represents the cookies of a storage state file, and satisfies the
+// [cookies.KeyedStore] and [cookies.IterStore] interfaces. The local storage
+// and other contents of the file are kept unchanged.
+type Store struct {
+	path  string
+	list  filestore.List[Cookie, *Cookie]
+	other map[string]json.RawMessage
+}
+
+// State returns the contents of s, as they would be written by Commit.
+func (s *Store) State() *State { return &State{Cookies: s.list.Cookies, Other: s.other} }
+
+// Scan implements part of the [cookies.Store] interface.
+func (s *Store) Scan(f cookies.ScanFunc) error { return s.list.Scan(f) }
+
+// All implements the [cookies.IterStore] interface. Cookies are reported in
+// the order of the "cookies" array.
+func (s *Store) All() iter.Seq2[cookies.C, error] { return s.list.All() }
+
+// Get implements part of the [cookies.KeyedStore] interface.
+func (s *Store) Get(key cookies.Key) (cookies.C, error) { return s.list.Get(key) }
+
+// Put implements part of the [cookies.KeyedStore] interface.  A new cookie is
+// appended to the "cookies" array.
+func (s *Store) Put(c cookies.C) error { return s.list.Put(c) }
+
+// Delete implements part of the [cookies.KeyedStore] interface.
+func (s *Store) Delete(key cookies.Key) error { return s.list.Delete(key) }
+
+// Commit implements part of the [cookies.Store] interface.  If the cookies
+// have changed, the whole state file is rewritten.
+func (s *Store) Commit() error {
+	return s.list.Commit(s.path, func(w io.Writer) error {
+		_, err := s.State().WriteTo(w)
+		return err
+	})
+}
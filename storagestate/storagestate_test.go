@@ -0,0 +1,212 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storagestate_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/creachadair/cookies"
+	"github.com/creachadair/cookies/storagestate"
+	"github.com/google/go-cmp/cmp"
+)
+
+const testState = `{
+  "cookies": [
+    {
+      "name": "sid",
+      "value": "abc123",
+      "domain": ".example.com",
+      "path": "/",
+      "expires": 1798761600,
+      "httpOnly": true,
+      "secure": true,
+      "sameSite": "Strict"
+    },
+    {
+      "name": "pref",
+      "value": "dark",
+      "domain": "www.example.com",
+      "path": "/app",
+      "expires": -1,
+      "httpOnly": false,
+      "secure": false,
+      "sameSite": "None"
+    }
+  ],
+  "origins": [
+    {
+      "origin": "https://www.example.com",
+      "localStorage": [{"name": "k", "value": "v"}]
+    }
+  ]
+}`
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, []byte(testState), 0600); err != nil {
+		t.Fatalf("Write file: %v", err)
+	}
+	s, err := storagestate.Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	var _ cookies.KeyedStore = s
+	var _ cookies.IterStore = s
+
+	want := []cookies.C{{
+		Name: "sid", Value: "abc123", Domain: "example.com", Path: "/",
+		Expires:  time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+		Flags:    cookies.Flags{Secure: true, HTTPOnly: true},
+		SameSite: cookies.Strict,
+	}, {
+		Name: "pref", Value: "dark", Domain: "www.example.com", Path: "/app",
+		Flags:    cookies.Flags{HostOnly: true},
+		SameSite: cookies.None,
+	}}
+	var got []cookies.C
+	for c, err := range s.All() {
+		if err != nil {
+			t.Fatalf("All: unexpected error: %v", err)
+		}
+		got = append(got, c)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Cookies (-want, +got):\n%s", diff)
+	}
+
+	// Discard one cookie, add another, and write the file back.
+	if err := s.Delete(want[1].Key()); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	added := cookies.C{Name: "new", Value: "1", Domain: "example.org", Path: "/", SameSite: cookies.Lax}
+	if err := s.Put(added); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := s.Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	s, err = storagestate.Open(path)
+	if err != nil {
+		t.Fatalf("Reopen: %v", err)
+	}
+	got = nil
+	for c := range s.All() {
+		got = append(got, c)
+	}
+	if diff := cmp.Diff([]cookies.C{want[0], added}, got); diff != "" {
+		t.Errorf("Cookies after update (-want, +got):\n%s", diff)
+	}
+
+	// The origins are preserved.
+	var orig, saved map[string]json.RawMessage
+	json.Unmarshal([]byte(testState), &orig)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Read file: %v", err)
+	}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatalf("Invalid output: %v", err)
+	}
+	if !jsonEqual(t, orig["origins"], saved["origins"]) {
+		t.Errorf("Origins changed: got %s, want %s", saved["origins"], orig["origins"])
+	}
+}
+
+func TestNew(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	s := storagestate.New(path)
+	if err := s.Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Read file: %v", err)
+	}
+	if !jsonEqual(t, []byte(`{"cookies":[],"origins":[]}`), data) {
+		t.Errorf("Empty state: got %s", data)
+	}
+}
+
+func TestOtherFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, []byte(`{"cookies": [{
+  "name": "sid", "value": "abc", "domain": "example.com", "path": "/",
+  "expires": -1, "httpOnly": false, "secure": true, "sameSite": "None",
+  "partitionKey": "https://example.org"
+}], "origins": []}`), 0600); err != nil {
+		t.Fatalf("Write file: %v", err)
+	}
+	s, err := storagestate.Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	// Change the value of the cookie. Its partition key should survive.
+	if err := s.Scan(func(e cookies.Editor) (cookies.Action, error) {
+		c := e.Get()
+		c.Value = "xyz"
+		if err := e.Set(c); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+		return cookies.Update, nil
+	}); err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if err := s.Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Read file: %v", err)
+	}
+	st, err := storagestate.Parse(data)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(st.Cookies) != 1 {
+		t.Fatalf("Got %d cookies, want 1", len(st.Cookies))
+	}
+	if got := st.Cookies[0].Value; got != "xyz" {
+		t.Errorf("Value: got %q, want xyz", got)
+	}
+	if got := string(st.Cookies[0].Other["partitionKey"]); got != `"https://example.org"` {
+		t.Errorf("partitionKey: got %s, want %q", got, "https://example.org")
+	}
+}
+
+func TestNullCookie(t *testing.T) {
+	st, err := storagestate.Parse([]byte(`{"cookies": [null], "origins": []}`))
+	if err == nil {
+		t.Errorf("Parse: got %+v, want error", st)
+	}
+}
+
+func jsonEqual(t *testing.T, a, b []byte) bool {
+	t.Helper()
+	var av, bv any
+	if err := json.Unmarshal(a, &av); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if err := json.Unmarshal(b, &bv); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	return cmp.Equal(av, bv)
+}
@@ -0,0 +1,137 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cookies
+
+import (
+	"cmp"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/creachadair/cookies/psl"
+)
+
+// Site returns the registrable domain (eTLD+1) of the domain of c, according
+// to the embedded copy of the Public Suffix List, such as "example.co.uk" for
+// a cookie from "www.example.co.uk". If the domain is an IP address or is
+// itself a public suffix, Site returns the domain in lower case.
+func Site(c C) string { return psl.Default().Site(c.Domain) }
+
+// MatchURL reports whether a user agent would send c in a request for u,
+// following the domain and path matching rules of RFC 6265 sections 5.1.3
+// and 5.1.4 and the selection rules of section 5.4. MatchURL does not check
+// whether c has expired.
+//
+// A host-only cookie matches only the host named by its domain. Otherwise,
+// the cookie matches any host equal to or within its domain. Host names are
+// compared without regard to case. A Secure cookie matches only "https" and
+// "wss" URLs.
+func MatchURL(c C, u *url.URL) bool {
+	if c.Flags.Secure && u.Scheme != "https" && u.Scheme != "wss" {
+		return false
+	}
+	return domainMatch(c, u.Hostname()) && pathMatch(c.Path, u.EscapedPath())
+}
+
+// domainMatch reports whether c should be sent to host.
+func domainMatch(c C, host string) bool {
+	host = strings.ToLower(host)
+	d := strings.ToLower(strings.TrimPrefix(c.Domain, "."))
+	if host == d {
+		return true
+	}
+	return !c.Flags.HostOnly && strings.HasSuffix(host, "."+d) && net.ParseIP(host) == nil
+}
+
+// pathMatch reports whether a cookie with the given path should be sent for
+// a request with path reqPath, as defined by RFC 6265 section 5.1.4.
+func pathMatch(cookiePath, reqPath string) bool {
+	if cookiePath == "" {
+		cookiePath = "/"
+	}
+	if !strings.HasPrefix(reqPath, "/") {
+		reqPath = "/"
+	}
+	rest, ok := strings.CutPrefix(reqPath, cookiePath)
+	if !ok {
+		return false
+	}
+	return rest == "" || strings.HasSuffix(cookiePath, "/") || strings.HasPrefix(rest, "/")
+}
+
+// ForURL returns the cookies in s that a user agent would send in a request
+// for u at the current time, in the order they would appear in the Cookie
+// header: Cookies with longer paths come first, and among cookies with paths
+// of equal length, those created earlier come first.
+//
+// Cookies that have expired are omitted. If s implements [FilteredStore],
+// ForURL selects only the cookies for the site of u with ScanWhere, so that
+// a database store need not read every cookie; otherwise ForURL reads s with
+// [All]. In either case, s is not modified.
+func ForURL(s Store, u *url.URL) ([]C, error) {
+	now := time.Now()
+	var out []C
+	add := func(c C) {
+		if !c.Expires.IsZero() && !c.Expires.After(now) {
+			return
+		}
+		if MatchURL(c, u) {
+			out = append(out, c)
+		}
+	}
+	if fs, ok := s.(FilteredStore); ok {
+		q := Query{Domain: psl.Default().Site(u.Hostname()), ExpiresAfter: now}
+		if err := fs.ScanWhere(q, func(e Editor) (Action, error) {
+			add(e.Get())
+			return Keep, nil
+		}); err != nil {
+			return nil, err
+		}
+	} else {
+		for c, err := range All(s) {
+			if err != nil {
+				return nil, err
+			}
+			add(c)
+		}
+	}
+	slices.SortStableFunc(out, func(a, b C) int {
+		if v := cmp.Compare(len(b.Path), len(a.Path)); v != 0 {
+			return v
+		}
+		return a.Created.Compare(b.Created)
+	})
+	return out, nil
+}
+
+// CookieHeader returns the value of a Cookie request header that sends the
+// specified cookies, in order. It returns "" if cs is empty. A nameless
+// cookie is sent as its value alone, as specified by RFC 6265bis.
+func CookieHeader(cs []C) string {
+	var sb strings.Builder
+	for i, c := range cs {
+		if i > 0 {
+			sb.WriteString("; ")
+		}
+		if c.Name != "" {
+			sb.WriteString(c.Name)
+			sb.WriteByte('=')
+		}
+		sb.WriteString(c.Value)
+	}
+	return sb.String()
+}
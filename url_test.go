@@ -0,0 +1,124 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cookies_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/creachadair/cookies"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMatchURL(t *testing.T) {
+	tests := []struct {
+		domain, path string
+		secure       bool
+		url          string
+		want         bool
+	}{
+		// Domain matching.
+		{".example.com", "/", false, "http://example.com/", true},
+		{".example.com", "/", false, "http://www.EXAMPLE.com/", true},
+		{".example.com", "/", false, "http://badexample.com/", false},
+		{"example.com", "/", false, "http://example.com/", true},
+		{"example.com", "/", false, "http://www.example.com/", false},
+		{"Example.COM", "/", false, "http://example.com:8080/", true},
+		{".0.0.1", "/", false, "http://127.0.0.1/", false},
+		{"127.0.0.1", "/", false, "http://127.0.0.1/", true},
+
+		// Path matching.
+		{"example.com", "", false, "http://example.com", true},
+		{"example.com", "/", false, "http://example.com", true},
+		{"example.com", "/docs", false, "http://example.com/docs", true},
+		{"example.com", "/docs", false, "http://example.com/docs/x", true},
+		{"example.com", "/docs/", false, "http://example.com/docs/x", true},
+		{"example.com", "/docs", false, "http://example.com/docsx", false},
+		{"example.com", "/docs/", false, "http://example.com/docs", false},
+		{"example.com", "/docs", false, "http://example.com/", false},
+
+		// Secure cookies.
+		{"example.com", "/", true, "http://example.com/", false},
+		{"example.com", "/", true, "https://example.com/", true},
+		{"example.com", "/", true, "wss://example.com/", true},
+	}
+	for _, tc := range tests {
+		u, err := url.Parse(tc.url)
+		if err != nil {
+			t.Fatalf("Parse %q: %v", tc.url, err)
+		}
+		domain, hostOnly := cookies.ParseHostKey(tc.domain)
+		c := cookies.C{Name: "x", Domain: domain, Path: tc.path,
+			Flags: cookies.Flags{Secure: tc.secure, HostOnly: hostOnly}}
+		if got := cookies.MatchURL(c, u); got != tc.want {
+			t.Errorf("MatchURL(%q %q secure=%v, %q): got %v, want %v",
+				tc.domain, tc.path, tc.secure, tc.url, got, tc.want)
+		}
+	}
+}
+
+func TestForURL(t *testing.T) {
+	now := time.Now()
+	s := &sliceStore{
+		{Name: "a", Value: "1", Domain: "example.com", Path: "/", Created: now.Add(-time.Hour)},
+		{Name: "b", Value: "2", Domain: "www.example.com", Path: "/docs", Created: now,
+			Flags: cookies.Flags{HostOnly: true}},
+		{Name: "c", Value: "3", Domain: "example.com", Path: "/", Created: now.Add(-2 * time.Hour)},
+		{Name: "d", Value: "4", Domain: ".example.com", Path: "/", Expires: now.Add(-time.Minute)},
+		{Name: "e", Value: "5", Domain: "other.com", Path: "/"},
+		{Name: "f", Value: "6", Domain: ".example.com", Path: "/docs/", Expires: now.Add(time.Hour)},
+	}
+	u, err := url.Parse("https://www.example.com/docs/index.html")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	cs, err := cookies.ForURL(s, u)
+	if err != nil {
+		t.Fatalf("ForURL: unexpected error: %v", err)
+	}
+	var got []string
+	for _, c := range cs {
+		got = append(got, c.Name)
+	}
+	if diff := cmp.Diff([]string{"f", "b", "c", "a"}, got); diff != "" {
+		t.Errorf("ForURL (-want, +got):\n%s", diff)
+	}
+	if got, want := cookies.CookieHeader(cs), "f=6; b=2; c=3; a=1"; got != want {
+		t.Errorf("CookieHeader: got %q, want %q", got, want)
+	}
+
+	// A nameless cookie is sent as its value alone.
+	nameless := []cookies.C{{Value: "x"}, {Name: "a", Value: "1"}}
+	if got, want := cookies.CookieHeader(nameless), "x; a=1"; got != want {
+		t.Errorf("CookieHeader: got %q, want %q", got, want)
+	}
+}
+
+func TestSite(t *testing.T) {
+	tests := []struct {
+		domain, want string
+	}{
+		{"www.example.com", "example.com"},
+		{".Shop.Example.CO.UK", "example.co.uk"},
+		{"co.uk", "co.uk"},
+		{"127.0.0.1", "127.0.0.1"},
+	}
+	for _, tc := range tests {
+		if got := cookies.Site(cookies.C{Domain: tc.domain}); got != tc.want {
+			t.Errorf("Site(%q): got %q, want %q", tc.domain, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cookies
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ErrInvalid is reported by [Validate] for a cookie that cannot be stored.
+// Implementations of [Editor] may also wrap it for format-specific limits.
+var ErrInvalid = errors.New("invalid cookie")
+
+const (
+	// maxNameValueSize is the maximum combined length in bytes of the name
+	// and value of a cookie.
+	maxNameValueSize = 4096
+
+	// maxAttrSize is the maximum length in bytes of the domain or path of a
+	// cookie.
+	maxAttrSize = 1024
+)
+
+// Validate reports whether c could be stored by a user agent following the
+// storage model of RFC 6265, with the size limits of its successor drafts.
+// If not, it reports an error wrapping [ErrInvalid] that describes the first
+// problem found.
+//
+// In particular, Validate requires that:
+//
+//   - The name and value are not both empty, and the name contains no
+//     control characters, ";", or "=". A cookie with an empty name and a
+//     non-empty value is a "nameless" cookie, as defined by RFC 6265bis.
+//   - The value contains no control characters or ";".
+//   - The domain is not empty, has no port, and contains no control
+//     characters, spaces, "/", or ";". If the cookie is host-only, the
+//     domain does not begin with a period.
+//   - The path is empty or begins with "/", and contains no control
+//     characters or ";".
+//   - The name and value together are at most 4096 bytes, and the domain
+//     and path are each at most 1024 bytes.
+//
+// Implementations of [Editor] call Validate from their Set methods.
+func Validate(c C) error {
+	if c.Name == "" && c.Value == "" {
+		return invalid("empty name and value")
+	} else if i := strings.IndexFunc(c.Name, isNameSpecial); i >= 0 {
+		return invalid("name %q contains %q", c.Name, c.Name[i])
+	}
+	if i := strings.IndexFunc(c.Value, isValueSpecial); i >= 0 {
+		return invalid("value of %q contains %q", c.Name, c.Value[i])
+	}
+	if n := len(c.Name) + len(c.Value); n > maxNameValueSize {
+		return invalid("name and value of %q are %d bytes (max %d)", c.Name, n, maxNameValueSize)
+	}
+
+	if c.Domain == "" {
+		return invalid("empty domain")
+	} else if i := strings.IndexFunc(c.Domain, isDomainSpecial); i >= 0 {
+		return invalid("domain %q contains %q", c.Domain, c.Domain[i])
+	} else if c.Flags.HostOnly && strings.HasPrefix(c.Domain, ".") {
+		return invalid("host-only domain %q begins with a period", c.Domain)
+	} else if strings.Contains(c.Domain, ":") && net.ParseIP(c.Domain) == nil {
+		return invalid("domain %q has a port", c.Domain)
+	} else if len(c.Domain) > maxAttrSize {
+		return invalid("domain is %d bytes (max %d)", len(c.Domain), maxAttrSize)
+	}
+
+	if c.Path != "" && !strings.HasPrefix(c.Path, "/") {
+		return invalid("path %q does not begin with /", c.Path)
+	} else if i := strings.IndexFunc(c.Path, isValueSpecial); i >= 0 {
+		return invalid("path %q contains %q", c.Path, c.Path[i])
+	} else if len(c.Path) > maxAttrSize {
+		return invalid("path is %d bytes (max %d)", len(c.Path), maxAttrSize)
+	}
+	return nil
+}
+
+func invalid(msg string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalid, fmt.Sprintf(msg, args...))
+}
+
+// isControl reports whether r is a control character as defined by RFC 5234.
+func isControl(r rune) bool { return r < 0x20 || r == 0x7f }
+
+func isValueSpecial(r rune) bool  { return isControl(r) || r == ';' }
+func isNameSpecial(r rune) bool   { return isValueSpecial(r) || r == '=' }
+func isDomainSpecial(r rune) bool { return isValueSpecial(r) || r == ' ' || r == '/' }
@@ -0,0 +1,69 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cookies_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/creachadair/cookies"
+)
+
+func TestValidate(t *testing.T) {
+	ok := cookies.C{Name: "sid", Value: "abc", Domain: ".example.com", Path: "/"}
+	with := func(f func(*cookies.C)) cookies.C { c := ok; f(&c); return c }
+
+	tests := []struct {
+		name string
+		c    cookies.C
+		ok   bool
+	}{
+		{"Valid", ok, true},
+		{"EmptyValue", with(func(c *cookies.C) { c.Value = "" }), true},
+		{"EmptyPath", with(func(c *cookies.C) { c.Path = "" }), true},
+		{"ValueSpace", with(func(c *cookies.C) { c.Value = "a b" }), true},
+		{"IPv4", with(func(c *cookies.C) { c.Domain = "127.0.0.1" }), true},
+		{"IPv6", with(func(c *cookies.C) { c.Domain = "::1" }), true},
+
+		{"Nameless", with(func(c *cookies.C) { c.Name = "" }), true},
+
+		{"EmptyNameValue", with(func(c *cookies.C) { c.Name, c.Value = "", "" }), false},
+		{"NameEquals", with(func(c *cookies.C) { c.Name = "a=b" }), false},
+		{"NameSemicolon", with(func(c *cookies.C) { c.Name = "a;b" }), false},
+		{"NameControl", with(func(c *cookies.C) { c.Name = "a\tb" }), false},
+		{"ValueNUL", with(func(c *cookies.C) { c.Value = "a\x00b" }), false},
+		{"ValueSemicolon", with(func(c *cookies.C) { c.Value = "a;b" }), false},
+		{"ValueDEL", with(func(c *cookies.C) { c.Value = "a\x7fb" }), false},
+		{"TooLong", with(func(c *cookies.C) { c.Value = strings.Repeat("x", 4094) }), false},
+		{"EmptyDomain", with(func(c *cookies.C) { c.Domain = "" }), false},
+		{"DomainPort", with(func(c *cookies.C) { c.Domain = "example.com:8080" }), false},
+		{"DomainSlash", with(func(c *cookies.C) { c.Domain = "example.com/x" }), false},
+		{"DomainSpace", with(func(c *cookies.C) { c.Domain = "example .com" }), false},
+		{"RelativePath", with(func(c *cookies.C) { c.Path = "foo" }), false},
+		{"PathControl", with(func(c *cookies.C) { c.Path = "/a\nb" }), false},
+		{"LongPath", with(func(c *cookies.C) { c.Path = "/" + strings.Repeat("p", 1024) }), false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := cookies.Validate(tc.c)
+			if tc.ok && err != nil {
+				t.Errorf("Validate: unexpected error: %v", err)
+			} else if !tc.ok && !errors.Is(err, cookies.ErrInvalid) {
+				t.Errorf("Validate: got %v, want %v", err, cookies.ErrInvalid)
+			}
+		})
+	}
+}
@@ -0,0 +1,275 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package webext supports reading and modifying the JSON cookie files
+// exported by browser extensions such as Cookie-Editor and EditThisCookie.
+//
+// These files contain a JSON array of cookie objects in the shape of the
+// WebExtensions cookies API (chrome.cookies.Cookie), with fields such as
+// "hostOnly", "session", "expirationDate", and "storeId". Fields of each
+// cookie that this package does not interpret are preserved when the file is
+// written back.
+package webext
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"math"
+	"os"
+	"time"
+
+	"github.com/creachadair/cookies"
+	"github.com/creachadair/cookies/internal/filestore"
+)
+
+// A Cookie is a single cookie in the WebExtensions format.
+//
+// A Cookie also satisfies [cookies.AttrEditor], with the following attribute:
+//
+//	storeId -- string, the ID of the cookie store holding the cookie
+type Cookie struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Domain   string `json:"domain"` // with a leading period for domain cookies
+	HostOnly bool   `json:"hostOnly"`
+	Path     string `json:"path"`
+	Secure   bool   `json:"secure"`
+	HTTPOnly bool   `json:"httpOnly"`
+
+	// One of "no_restriction", "lax", "strict", or "unspecified".
+	SameSite string `json:"sameSite"`
+
+	Session bool `json:"session"`
+
+	// The expiration time in seconds since the Unix epoch. It is omitted for
+	// a session cookie.
+	ExpirationDate float64 `json:"expirationDate,omitempty"`
+
+	StoreID string `json:"storeId,omitempty"`
+
+	// Other fields of the cookie object, keyed by name.
+	Other map[string]json.RawMessage `json:"-"`
+}
+
+// knownFields are the JSON field names decoded into the fields of a Cookie.
+var knownFields = []string{
+	"name", "value", "domain", "hostOnly", "path", "secure", "httpOnly",
+	"sameSite", "session", "expirationDate", "storeId",
+}
+
+// UnmarshalJSON implements the [json.Unmarshaler] interface.
+func (c *Cookie) UnmarshalJSON(data []byte) error {
+	type plain Cookie
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	var other map[string]json.RawMessage
+	if err := json.Unmarshal(data, &other); err != nil {
+		return err
+	}
+	for _, name := range knownFields {
+		delete(other, name)
+	}
+	if len(other) == 0 {
+		other = nil
+	}
+	*c = Cookie(p)
+	c.Other = other
+	return nil
+}
+
+// MarshalJSON implements the [json.Marshaler] interface.
+func (c *Cookie) MarshalJSON() ([]byte, error) {
+	type plain Cookie
+	data, err := json.Marshal((*plain)(c))
+	if err != nil || len(c.Other) == 0 {
+		return data, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for name, v := range c.Other {
+		if _, ok := fields[name]; !ok {
+			fields[name] = v
+		}
+	}
+	return json.Marshal(fields)
+}
+
+// Get returns a format-independent representation of c.
+// It satisfies part of [cookies.Editor].
+func (c *Cookie) Get() cookies.C {
+	domain, _ := cookies.ParseHostKey(c.Domain)
+	out := cookies.C{
+		Name:   c.Name,
+		Value:  c.Value,
+		Domain: domain,
+		Path:   c.Path,
+		Flags: cookies.Flags{
+			Secure:   c.Secure,
+			HTTPOnly: c.HTTPOnly,
+			HostOnly: c.HostOnly,
+		},
+		SameSite: decodeSitePolicy(c.SameSite),
+	}
+	if !c.Session {
+		sec, frac := math.Modf(c.ExpirationDate)
+		out.Expires = time.Unix(int64(sec), int64(frac*1e9)).UTC()
+	}
+	return out
+}
+
+// Set updates c to match the contents of o. The format does not record the
+// creation time of a cookie, so o.Created is discarded.
+// It satisfies part of [cookies.Editor].
+func (c *Cookie) Set(o cookies.C) error {
+	if err := cookies.Validate(o); err != nil {
+		return err
+	}
+	c.Name = o.Name
+	c.Value = o.Value
+	c.Domain = o.HostKey()
+	c.HostOnly = o.Flags.HostOnly
+	c.Path = o.Path
+	c.Secure = o.Flags.Secure
+	c.HTTPOnly = o.Flags.HTTPOnly
+	c.SameSite = encodeSitePolicy(o.SameSite)
+	c.Session = o.Expires.IsZero()
+	c.ExpirationDate = 0
+	if !c.Session {
+		c.ExpirationDate = float64(o.Expires.UnixNano()) / 1e9
+	}
+	return nil
+}
+
+// Attrs returns the format-specific attributes of c.
+// It satisfies part of [cookies.AttrEditor].
+func (c *Cookie) Attrs() map[string]any { return map[string]any{"storeId": c.StoreID} }
+
+// SetAttr sets the named format-specific attribute of c.
+// It satisfies part of [cookies.AttrEditor].
+func (c *Cookie) SetAttr(name string, value any) error {
+	switch name {
+	case "storeId":
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("attribute %q: got %T, want string", name, value)
+		}
+		c.StoreID = v
+		return nil
+	default:
+		return fmt.Errorf("unknown attribute %q", name)
+	}
+}
+
+func decodeSitePolicy(s string) cookies.SameSite {
+	switch s {
+	case "strict":
+		return cookies.Strict
+	case "lax":
+		return cookies.Lax
+	case "no_restriction":
+		return cookies.None
+	default:
+		return cookies.Unknown
+	}
+}
+
+func encodeSitePolicy(p cookies.SameSite) string {
+	switch p {
+	case cookies.Strict:
+		return "strict"
+	case cookies.Lax:
+		return "lax"
+	case cookies.None:
+		return "no_restriction"
+	default:
+		return "unspecified"
+	}
+}
+
+// Open opens a JSON cookie file and returns a Store containing its data.
+func Open(path string) (*Store, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := &Store{path: path}
+	if err := json.Unmarshal(data, &s.list.Cookies); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// New returns an empty Store that will be written to path by Commit.
+func New(path string) *Store {
+	s := &Store{path: path}
+	s.list.Dirty = true
+	return s
+}
+
+// A Store represents an exported cookie file, and satisfies the
+// [cookies.KeyedStore] and [cookies.IterStore] interfaces.
+type Store struct {
+	path string
+	list filestore.List[Cookie, *Cookie]
+}
+
+// Cookies returns the cookies in s, in the order they appear in the file.
+func (s *Store) Cookies() []*Cookie { return s.list.Cookies }
+
+// WriteTo encodes the cookies in s as a JSON array to w.
+func (s *Store) WriteTo(w io.Writer) (int64, error) {
+	cs := s.list.Cookies
+	if cs == nil {
+		cs = []*Cookie{}
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(cs); err != nil {
+		return 0, err
+	}
+	return buf.WriteTo(w)
+}
+
+// Scan implements part of the [cookies.Store] interface.
+func (s *Store) Scan(f cookies.ScanFunc) error { return s.list.Scan(f) }
+
+// All implements the [cookies.IterStore] interface, reporting the cookies in
+// the order of the exported array.
+func (s *Store) All() iter.Seq2[cookies.C, error] { return s.list.All() }
+
+// Get implements part of the [cookies.KeyedStore] interface.
+func (s *Store) Get(key cookies.Key) (cookies.C, error) { return s.list.Get(key) }
+
+// Put implements part of the [cookies.KeyedStore] interface.  An existing
+// cookie keeps its store ID and any fields this package does not interpret.
+func (s *Store) Put(c cookies.C) error { return s.list.Put(c) }
+
+// Delete implements part of the [cookies.KeyedStore] interface.
+func (s *Store) Delete(key cookies.Key) error { return s.list.Delete(key) }
+
+// Commit implements part of the [cookies.Store] interface.
+func (s *Store) Commit() error {
+	return s.list.Commit(s.path, func(w io.Writer) error {
+		_, err := s.WriteTo(w)
+		return err
+	})
+}
@@ -0,0 +1,163 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webext_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/creachadair/cookies"
+	"github.com/creachadair/cookies/webext"
+	"github.com/google/go-cmp/cmp"
+)
+
+// testExport is in the format written by the Cookie-Editor extension.
+const testExport = `[
+  {
+    "domain": ".example.com",
+    "expirationDate": 1798761600.5,
+    "hostOnly": false,
+    "httpOnly": true,
+    "name": "sid",
+    "path": "/",
+    "sameSite": "no_restriction",
+    "secure": true,
+    "session": false,
+    "storeId": "0",
+    "value": "abc123",
+    "id": 1
+  },
+  {
+    "domain": "www.example.com",
+    "hostOnly": true,
+    "httpOnly": false,
+    "name": "pref",
+    "path": "/app",
+    "sameSite": "unspecified",
+    "secure": false,
+    "session": true,
+    "storeId": "0",
+    "value": "dark",
+    "id": 2
+  }
+]`
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cookies.json")
+	if err := os.WriteFile(path, []byte(testExport), 0600); err != nil {
+		t.Fatalf("Write file: %v", err)
+	}
+	s, err := webext.Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	var _ cookies.KeyedStore = s
+	var _ cookies.IterStore = s
+
+	want := []cookies.C{{
+		Name: "sid", Value: "abc123", Domain: "example.com", Path: "/",
+		Expires:  time.Date(2027, 1, 1, 0, 0, 0, 5e8, time.UTC),
+		Flags:    cookies.Flags{Secure: true, HTTPOnly: true},
+		SameSite: cookies.None,
+	}, {
+		Name: "pref", Value: "dark", Domain: "www.example.com", Path: "/app",
+		Flags: cookies.Flags{HostOnly: true},
+	}}
+	var got []cookies.C
+	for c, err := range s.All() {
+		if err != nil {
+			t.Fatalf("All: unexpected error: %v", err)
+		}
+		got = append(got, c)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Cookies (-want, +got):\n%s", diff)
+	}
+
+	// Update one cookie, discard another, add a third, and write the file back.
+	want[0].Value = "xyz789"
+	if err := s.Put(want[0]); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := s.Delete(want[1].Key()); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	added := cookies.C{Name: "new", Value: "1", Domain: "example.org", Path: "/", SameSite: cookies.Strict}
+	if err := s.Put(added); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := s.Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	s, err = webext.Open(path)
+	if err != nil {
+		t.Fatalf("Reopen: %v", err)
+	}
+	got = nil
+	for c := range s.All() {
+		got = append(got, c)
+	}
+	if diff := cmp.Diff([]cookies.C{want[0], added}, got); diff != "" {
+		t.Errorf("Cookies after update (-want, +got):\n%s", diff)
+	}
+
+	// Fields not interpreted by the package are preserved.
+	cs := s.Cookies()
+	if id := string(cs[0].Other["id"]); id != "1" {
+		t.Errorf("Updated cookie id: got %q, want 1", id)
+	}
+	if got := cs[0].Attrs()["storeId"]; got != "0" {
+		t.Errorf("Updated cookie storeId: got %v, want 0", got)
+	}
+	if cs[1].Session != true || cs[1].SameSite != "strict" {
+		t.Errorf("Added cookie: got session=%v sameSite=%q, want true, strict", cs[1].Session, cs[1].SameSite)
+	}
+}
+
+func TestAttrs(t *testing.T) {
+	var c webext.Cookie
+	if err := c.SetAttr("storeId", "1"); err != nil {
+		t.Fatalf("SetAttr: unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(map[string]any{"storeId": "1"}, c.Attrs()); diff != "" {
+		t.Errorf("Attrs (-want, +got):\n%s", diff)
+	}
+	if err := c.SetAttr("storeId", 1); err == nil {
+		t.Error("SetAttr with wrong type: got nil, want error")
+	}
+	if err := c.SetAttr("nonesuch", "x"); err == nil {
+		t.Error("SetAttr unknown: got nil, want error")
+	}
+}
+
+func TestNew(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cookies.json")
+	s := webext.New(path)
+	if err := s.Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Read file: %v", err)
+	}
+	var v []any
+	if err := json.Unmarshal(data, &v); err != nil || v == nil || len(v) != 0 {
+		t.Errorf("Empty store: got %s, %v; want []", data, err)
+	}
+}
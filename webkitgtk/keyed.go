@@ -0,0 +1,99 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webkitgtk
+
+import (
+	"context"
+	"math"
+
+	"github.com/creachadair/cookies"
+	"github.com/creachadair/cookies/internal/dbfile"
+)
+
+// Get implements part of the [cookies.KeyedStore] interface.
+func (s *Store) Get(key cookies.Key) (cookies.C, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return cookies.C{}, err
+	}
+	defer tx.Rollback()
+
+	cs, err := s.readCookies(context.Background(), tx, math.MinInt64, 1,
+		[]string{"host = ?", "name = ?", "path = ?"}, []any{key.Domain, key.Name, key.Path})
+	if err != nil {
+		return cookies.C{}, err
+	} else if len(cs) == 0 {
+		return cookies.C{}, cookies.ErrNotFound
+	}
+	return cs[0].C, nil
+}
+
+// Put implements part of the [cookies.KeyedStore] interface. The change takes
+// effect immediately. It reports an error if c is a session cookie, which the
+// database cannot hold.
+func (s *Store) Put(c cookies.C) error {
+	if s.readOnly {
+		return dbfile.ErrReadOnly
+	}
+	if err := checkCookie(c); err != nil {
+		return err
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	set, args := s.sameSiteUpdate(c)
+	args = append([]any{
+		c.Value, c.Expires.Unix(), boolToInt(c.Flags.Secure), boolToInt(c.Flags.HTTPOnly),
+	}, args...)
+	res, err := tx.Exec(`UPDATE moz_cookies SET `+
+		`value = ?, expiry = ?, isSecure = ?, isHttpOnly = ?`+set+` `+
+		`WHERE host = ? AND name = ? AND path = ?`,
+		append(args, c.HostKey(), c.Name, c.Path)...,
+	)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		if err := dbfile.Insert(tx, "moz_cookies", map[string]any{
+			"name":       c.Name,
+			"value":      c.Value,
+			"host":       c.HostKey(),
+			"path":       c.Path,
+			"expiry":     c.Expires.Unix(),
+			"isSecure":   boolToInt(c.Flags.Secure),
+			"isHttpOnly": boolToInt(c.Flags.HTTPOnly),
+			"sameSite":   encodeSitePolicy(c.SameSite),
+		}); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Delete implements part of the [cookies.KeyedStore] interface. The change
+// takes effect immediately.
+func (s *Store) Delete(key cookies.Key) error {
+	if s.readOnly {
+		return dbfile.ErrReadOnly
+	}
+	_, err := s.db.Exec(`DELETE FROM moz_cookies WHERE host = ? AND name = ? AND path = ?`,
+		key.Domain, key.Name, key.Path)
+	return err
+}
@@ -0,0 +1,374 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package webkitgtk supports reading and modifying the cookie database of
+// WebKitGTK browsers, such as GNOME Web (Epiphany).
+//
+// WebKitGTK stores cookies using libsoup, in a "cookies.sqlite" database
+// whose moz_cookies table resembles that of Firefox, but lacks most of its
+// columns. In particular, the database does not record the creation time of
+// a cookie, and holds only persistent cookies: Session cookies are never
+// written, and cookies that have expired are ignored when it is loaded.
+//
+// WebKitGTK does not mark its profile as in use, so unlike the firefox
+// package, Open cannot detect a running browser. Since the browser rewrites
+// the database as its cookies change, changes should be made only while it
+// is not running.
+package webkitgtk
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"iter"
+	"strings"
+	"time"
+
+	"github.com/creachadair/cookies"
+	"github.com/creachadair/cookies/internal/dbfile"
+)
+
+// Open opens the WebKitGTK cookie database at the specified path.
+// If opts == nil, default options are used.
+func Open(path string, opts *Options) (*Store, error) {
+	var snap *dbfile.Snapshot
+	if opts.snapshot() {
+		var err error
+		snap, err = dbfile.NewSnapshot(path)
+		if err != nil {
+			return nil, fmt.Errorf("snapshot: %w", err)
+		}
+		path = snap.Path
+	}
+	db, err := sql.Open(opts.driver(), dbfile.URI(path, opts.readOnly() && snap == nil))
+	if err != nil {
+		if snap != nil {
+			snap.Close()
+		}
+		return nil, err
+	}
+	s, err := OpenDB(db, opts)
+	if err != nil {
+		db.Close()
+		if snap != nil {
+			snap.Close()
+		}
+		return nil, err
+	}
+	s.ownDB = true
+	s.readOnly = s.readOnly || snap != nil
+	s.snap = snap
+	return s, nil
+}
+
+// OpenDB returns a Store that reads and modifies the WebKitGTK cookie
+// database accessed through db. The caller remains responsible for closing
+// db; the Close method of the resulting store does not close it. OpenDB
+// reports an error if db has no moz_cookies table.
+//
+// The Driver and Snapshot options do not apply to OpenDB.
+func OpenDB(db *sql.DB, opts *Options) (*Store, error) {
+	cols, err := tableColumns(db)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{db: db, readOnly: opts.readOnly(), hasSameSite: cols["sameSite"]}, nil
+}
+
+// tableColumns reports the columns of the cookies table of db, or an error
+// if it has no such table.
+func tableColumns(db *sql.DB) (map[string]bool, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	return dbfile.Columns(tx, "moz_cookies")
+}
+
+// IsSchema reports whether the database accessed through db has the
+// WebKitGTK cookie schema, as opposed to that of Firefox, which uses the same
+// table name. The sameSite column, which older versions of WebKitGTK do not
+// have, is not required.
+func IsSchema(db *sql.DB) (bool, error) {
+	cols, err := tableColumns(db)
+	if err != nil {
+		return false, err
+	}
+	for _, name := range []string{"id", "name", "value", "host", "path", "expiry", "isSecure", "isHttpOnly"} {
+		if !cols[name] {
+			return false, nil
+		}
+	}
+	return !cols["originAttributes"] && !cols["creationTime"], nil
+}
+
+// Options are optional settings for a Store.
+// A nil *Options is ready for use with default settings.
+type Options struct {
+	// If true, open the database read-only and treat it as immutable, so that
+	// no locks are taken. Since there is no way to tell whether a browser is
+	// using the database, and libsoup rewrites it as cookies change, this may
+	// give incorrect results or a corruption error if a browser is running.
+	// To read the cookies of a browser that may be running, use Snapshot.
+	ReadOnly bool
+
+	// If true, copy cookies.sqlite and any journal into a temporary
+	// directory and open the copy, retrying if libsoup rewrites the database
+	// during the copy. The resulting store is read-only, and must be closed
+	// to remove the copy.
+	Snapshot bool
+
+	// The name of the database/sql driver to use to open the database.  The
+	// driver must be registered by the caller, typically with a blank import.
+	// If empty, "sqlite" is used, as registered by modernc.org/sqlite.
+	Driver string
+}
+
+func (o *Options) driver() string {
+	if o == nil || o.Driver == "" {
+		return "sqlite"
+	}
+	return o.Driver
+}
+
+func (o *Options) readOnly() bool { return o != nil && o.ReadOnly }
+func (o *Options) snapshot() bool { return o != nil && o.Snapshot }
+
+// A Store connects to a collection of cookies stored in an SQLite database
+// using the WebKitGTK cookie schema.
+type Store struct {
+	db          *sql.DB
+	readOnly    bool
+	hasSameSite bool             // whether the table has a sameSite column
+	ownDB       bool             // whether Close should close db
+	snap        *dbfile.Snapshot // if opened from a snapshot
+}
+
+// Scan implements part of the [cookies.Store] interface.
+func (s *Store) Scan(f cookies.ScanFunc) error {
+	return s.scanWhere(context.Background(), cookies.Query{}, f)
+}
+
+// ScanContext implements the [cookies.ContextStore] interface.  If ctx ends
+// before the scan is complete, the scan stops, no changes are applied, and
+// ScanContext reports the error from ctx.
+func (s *Store) ScanContext(ctx context.Context, f cookies.ScanFunc) error {
+	return s.scanWhere(ctx, cookies.Query{}, f)
+}
+
+// All implements the [cookies.IterStore] interface.  Cookies are read in batches
+// of increasing ID within a read-only transaction.
+func (s *Store) All() iter.Seq2[cookies.C, error] { return s.table().All() }
+
+// ScanWhere implements the [cookies.FilteredStore] interface.  The conditions
+// of q are evaluated by the database, so only matching cookies are read.
+//
+// Since libsoup rewrites the whole table as its cookies change, the database
+// may be large; rows are read a batch at a time so that it is not held in
+// memory at once. All changes are applied in a single transaction, which is
+// committed only if the scan completes without error.
+func (s *Store) ScanWhere(q cookies.Query, f cookies.ScanFunc) error {
+	return s.scanWhere(context.Background(), q, f)
+}
+
+func (s *Store) scanWhere(ctx context.Context, q cookies.Query, f cookies.ScanFunc) error {
+	return s.table().Scan(ctx, q, f)
+}
+
+// table returns a scanner for the moz_cookies table of s.
+func (s *Store) table() *dbfile.Table[*Cookie] {
+	return &dbfile.Table[*Cookie]{
+		DB:       s.db,
+		ReadOnly: s.readOnly,
+		Filter: dbfile.Filter{
+			Host:         "host",
+			Name:         "name",
+			Expires:      "expiry",
+			ExpiresValue: dbfile.CeilUnix, // expiry is in whole seconds
+		},
+		Read:  s.readCookies,
+		ID:    func(c *Cookie) int64 { return c.id },
+		Write: s.writeCookie,
+		Drop:  s.dropCookie,
+	}
+}
+
+// ReadOnly reports whether s was opened read-only, either by the ReadOnly
+// option or from a snapshot. Changes to a read-only store report an error.
+func (s *Store) ReadOnly() bool { return s.readOnly }
+
+// Commit implements part of the [cookies.Store] interface.
+func (s *Store) Commit() error { return nil }
+
+// Close closes the database, and removes its snapshot if it has one.  If s was
+// created by OpenDB, Close does not close the database.
+func (s *Store) Close() error {
+	var err error
+	if s.ownDB {
+		err = s.db.Close()
+	}
+	if s.snap != nil {
+		err = errors.Join(err, s.snap.Close())
+	}
+	return err
+}
+
+// A Cookie represents a single cookie from a WebKitGTK database.
+type Cookie struct {
+	cookies.C
+
+	id int64
+}
+
+// Get implements part of the [cookies.Editor] interface.
+func (c *Cookie) Get() cookies.C { return c.C }
+
+// Set implements part of the [cookies.Editor] interface.
+// It reports an error if o is not valid according to [cookies.Validate], or
+// is a session cookie, which the database cannot hold. The creation time of
+// o is not stored.
+func (c *Cookie) Set(o cookies.C) error {
+	if err := checkCookie(o); err != nil {
+		return err
+	}
+	c.C = o
+	return nil
+}
+
+// checkCookie reports whether c can be stored in the database.
+func checkCookie(c cookies.C) error {
+	if err := cookies.Validate(c); err != nil {
+		return err
+	} else if c.Expires.IsZero() {
+		return fmt.Errorf("%w: session cookie %q cannot be stored", cookies.ErrInvalid, c.Name)
+	}
+	return nil
+}
+
+// readCookies reads up to limit cookies satisfying the SQL conditions in conds
+// whose IDs are greater than after, in order of increasing ID.
+func (s *Store) readCookies(ctx context.Context, tx *sql.Tx, after int64, limit int, conds []string, args []any) ([]*Cookie, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT `+
+		`id, name, value, host, path, expiry, isSecure, isHttpOnly, `+s.sameSiteColumn()+` `+
+		`FROM moz_cookies WHERE `+strings.Join(append([]string{"id > ?"}, conds...), " AND ")+
+		` ORDER BY id LIMIT ?`, append(append([]any{after}, args...), limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cs []*Cookie
+	for rows.Next() {
+		var rowID, expiry int64
+		var sameSite sql.NullInt64
+		var isSecure, isHTTPOnly bool
+		var name, value, host, path string
+
+		if err := rows.Scan(&rowID, &name, &value, &host, &path, &expiry,
+			&isSecure, &isHTTPOnly, &sameSite); err != nil {
+			return nil, err
+		}
+
+		domain, hostOnly := cookies.ParseHostKey(host)
+		cs = append(cs, &Cookie{
+			C: cookies.C{
+				Name:    name,
+				Value:   value,
+				Domain:  domain,
+				Path:    path,
+				Expires: time.Unix(expiry, 0).UTC(),
+				Flags: cookies.Flags{
+					Secure:   isSecure,
+					HTTPOnly: isHTTPOnly,
+					HostOnly: hostOnly,
+				},
+				SameSite: decodeSitePolicy(sameSite.Int64),
+			},
+			id: rowID,
+		})
+	}
+	return cs, rows.Err()
+}
+
+func (s *Store) dropCookie(w *dbfile.Writer, c *Cookie) error {
+	return w.Exec(`DELETE FROM moz_cookies WHERE id = ?`, c.id)
+}
+
+func (s *Store) writeCookie(w *dbfile.Writer, c *Cookie) error {
+	set, args := s.sameSiteUpdate(c.C)
+	args = append([]any{
+		c.Name, c.Value, c.HostKey(), c.Path, c.Expires.Unix(),
+		boolToInt(c.Flags.Secure), boolToInt(c.Flags.HTTPOnly),
+	}, args...)
+	return w.Exec(`UPDATE moz_cookies SET `+
+		`name = ?, value = ?, host = ?, path = ?, expiry = ?, `+
+		`isSecure = ?, isHttpOnly = ?`+set+` `+
+		`WHERE id = ?`, append(args, c.id)...)
+}
+
+// sameSiteColumn returns the expression selecting the SameSite policy of a
+// cookie. For a table without a sameSite column, the policy is NULL, which is
+// decoded as Unknown.
+func (s *Store) sameSiteColumn() string {
+	if s.hasSameSite {
+		return "sameSite"
+	}
+	return "NULL"
+}
+
+// sameSiteUpdate returns an SQL assignment to follow the other columns of an
+// UPDATE, setting the SameSite policy of c, and its argument. For a table
+// without a sameSite column, both are empty.
+func (s *Store) sameSiteUpdate(c cookies.C) (string, []any) {
+	if s.hasSameSite {
+		return ", sameSite = ?", []any{encodeSitePolicy(c.SameSite)}
+	}
+	return "", nil
+}
+
+func boolToInt(ok bool) int {
+	if ok {
+		return 1
+	}
+	return 0
+}
+
+// decodeSitePolicy decodes a libsoup SoupSameSitePolicy value. When loading
+// the database, libsoup treats 0 as unset, and applies its default policy.
+func decodeSitePolicy(ss int64) cookies.SameSite {
+	switch ss {
+	case 1:
+		return cookies.Lax
+	case 2:
+		return cookies.Strict
+	default:
+		return cookies.Unknown
+	}
+}
+
+// encodeSitePolicy encodes ss as a libsoup SoupSameSitePolicy value.  The
+// None policy is stored as 0, the same as an unspecified policy, as libsoup
+// does.
+func encodeSitePolicy(ss cookies.SameSite) int {
+	switch ss {
+	case cookies.Lax:
+		return 1
+	case cookies.Strict:
+		return 2
+	default:
+		return 0
+	}
+}
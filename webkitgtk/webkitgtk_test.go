@@ -0,0 +1,349 @@
+// Copyright 2026 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webkitgtk_test
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/creachadair/cookies"
+	"github.com/creachadair/cookies/internal/dbfile/dbtest"
+	"github.com/creachadair/cookies/webkitgtk"
+	"github.com/google/go-cmp/cmp"
+
+	_ "modernc.org/sqlite"
+)
+
+// createTable is the schema created by libsoup (SoupCookieJarDB).
+const createTable = `CREATE TABLE moz_cookies (id INTEGER PRIMARY KEY, name TEXT, value TEXT, ` +
+	`host TEXT, path TEXT, expiry INTEGER, lastAccessed INTEGER, isSecure INTEGER, ` +
+	`isHttpOnly INTEGER, sameSite INTEGER)`
+
+// oldCreateTable is the schema created by older versions of libsoup, which
+// have no sameSite column.
+const oldCreateTable = `CREATE TABLE moz_cookies (id INTEGER PRIMARY KEY, name TEXT, value TEXT, ` +
+	`host TEXT, path TEXT, expiry INTEGER, lastAccessed INTEGER, isSecure INTEGER, ` +
+	`isHttpOnly INTEGER)`
+
+// newTestDB creates a WebKitGTK cookie database in a temporary directory,
+// populated with the given cookies, and returns its path.
+func newTestDB(t testing.TB, cs ...cookies.C) string {
+	t.Helper()
+	path := dbtest.NewDB(t, "cookies.sqlite", createTable)
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("Open test database: %v", err)
+	}
+	defer db.Close()
+	for _, c := range cs {
+		// This matches the insert statement used by libsoup.
+		if _, err := db.Exec(`INSERT INTO moz_cookies VALUES(NULL, ?, ?, ?, ?, ?, NULL, ?, ?, ?)`,
+			c.Name, c.Value, c.HostKey(), c.Path, c.Expires.Unix(),
+			c.Flags.Secure, c.Flags.HTTPOnly, sameSiteValue[c.SameSite]); err != nil {
+			t.Fatalf("Insert cookie: %v", err)
+		}
+	}
+	return path
+}
+
+// sameSiteValue maps generic SameSite policies to libsoup values.
+var sameSiteValue = map[cookies.SameSite]int{cookies.Lax: 1, cookies.Strict: 2}
+
+func TestRead(t *testing.T) {
+	exp := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	want := []cookies.C{
+		{Name: "a", Value: "1", Domain: "example.com", Path: "/", Expires: exp,
+			Flags: cookies.Flags{Secure: true, HTTPOnly: true}, SameSite: cookies.Strict},
+		{Name: "b", Value: "2", Domain: "www.example.com", Path: "/x", Expires: exp,
+			Flags: cookies.Flags{HostOnly: true}, SameSite: cookies.Lax},
+		{Name: "c", Value: "3", Domain: "example.org", Path: "/", Expires: exp},
+	}
+	s, err := webkitgtk.Open(newTestDB(t, want...), &webkitgtk.Options{ReadOnly: true})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+	var _ cookies.IterStore = s
+
+	var got []cookies.C
+	for c, err := range s.All() {
+		if err != nil {
+			t.Fatalf("All: unexpected error: %v", err)
+		}
+		got = append(got, c)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Cookies (-want, +got):\n%s", diff)
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	exp := time.Now().Add(time.Hour)
+	path := newTestDB(t,
+		cookies.C{Name: "a", Domain: ".example.com", Path: "/", Expires: exp},
+		cookies.C{Name: "b", Domain: "example.com", Path: "/", Expires: exp},
+	)
+	discard := func(cookies.Editor) (cookies.Action, error) { return cookies.Discard, nil }
+
+	for _, opts := range []*webkitgtk.Options{{ReadOnly: true}, {Snapshot: true}} {
+		s, err := webkitgtk.Open(path, opts)
+		if err != nil {
+			t.Fatalf("Open %+v: %v", opts, err)
+		}
+		if err := s.Scan(discard); err == nil {
+			t.Errorf("Scan %+v with Discard: got nil, want error", opts)
+		}
+		if err := s.Close(); err != nil {
+			t.Errorf("Close %+v: %v", opts, err)
+		}
+	}
+
+	s, err := webkitgtk.Open(path, nil)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+	if diff := cmp.Diff([]string{"a", "b"}, dbtest.ScanNames(t, s)); diff != "" {
+		t.Errorf("Names: (-want, +got)\n%s", diff)
+	}
+}
+
+func TestScanWhere(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	all := []cookies.C{
+		{Name: "a", Domain: ".example.com", Path: "/", Expires: now.Add(time.Hour)},
+		{Name: "b", Domain: "www.example.com", Path: "/", Expires: now.Add(-time.Hour)},
+		{Name: "a", Domain: "badexample.com", Path: "/", Expires: now.Add(time.Hour)},
+	}
+	s, err := webkitgtk.Open(newTestDB(t, all...), nil)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+
+	for _, q := range []cookies.Query{
+		{},
+		{Domain: "example.com"},
+		{Name: "a"},
+		{ExpiresBefore: now},
+	} {
+		var want, got []string
+		for _, c := range all {
+			if q.Match(c) {
+				want = append(want, c.HostKey()+":"+c.Name)
+			}
+		}
+		if err := s.ScanWhere(q, func(e cookies.Editor) (cookies.Action, error) {
+			c := e.Get()
+			got = append(got, c.HostKey()+":"+c.Name)
+			return cookies.Keep, nil
+		}); err != nil {
+			t.Fatalf("ScanWhere %+v: %v", q, err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("ScanWhere %+v: (-want, +got)\n%s", q, diff)
+		}
+	}
+}
+
+func TestKeyed(t *testing.T) {
+	exp := time.Now().Add(time.Hour).Truncate(time.Second).UTC()
+	a := cookies.C{Name: "a", Value: "1", Domain: "example.com", Path: "/", Expires: exp, SameSite: cookies.Lax}
+	b := cookies.C{Name: "b", Value: "2", Domain: "example.com", Path: "/", Expires: exp}
+	s, err := webkitgtk.Open(newTestDB(t, a), nil)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+	var _ cookies.KeyedStore = s
+
+	mustGet := func(key cookies.Key, want cookies.C) {
+		t.Helper()
+		got, err := s.Get(key)
+		if err != nil {
+			t.Fatalf("Get %v: unexpected error: %v", key, err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("Get %v: (-want, +got)\n%s", key, diff)
+		}
+	}
+
+	mustGet(a.Key(), a)
+	if _, err := s.Get(b.Key()); !errors.Is(err, cookies.ErrNotFound) {
+		t.Errorf("Get %v: got %v, want %v", b.Key(), err, cookies.ErrNotFound)
+	}
+
+	// Put of a new key inserts a cookie.
+	if err := s.Put(b); err != nil {
+		t.Fatalf("Put %v: %v", b.Key(), err)
+	}
+	mustGet(b.Key(), b)
+
+	// Put of an existing key replaces the cookie. The None policy is stored
+	// as unspecified.
+	a.Value = "updated"
+	a.Flags.Secure = true
+	a.SameSite = cookies.None
+	if err := s.Put(a); err != nil {
+		t.Fatalf("Put %v: %v", a.Key(), err)
+	}
+	a.SameSite = cookies.Unknown
+	mustGet(a.Key(), a)
+
+	// A session cookie cannot be stored.
+	if err := s.Put(cookies.C{Name: "s", Domain: "example.com", Path: "/"}); !errors.Is(err, cookies.ErrInvalid) {
+		t.Errorf("Put session cookie: got %v, want %v", err, cookies.ErrInvalid)
+	}
+	if err := s.Scan(func(e cookies.Editor) (cookies.Action, error) {
+		c := e.Get()
+		c.Expires = time.Time{}
+		if err := e.Set(c); !errors.Is(err, cookies.ErrInvalid) {
+			t.Errorf("Set session cookie: got %v, want %v", err, cookies.ErrInvalid)
+		}
+		return cookies.Keep, nil
+	}); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+
+	if err := s.Delete(a.Key()); err != nil {
+		t.Fatalf("Delete %v: %v", a.Key(), err)
+	}
+	if diff := cmp.Diff([]string{"b"}, dbtest.ScanNames(t, s)); diff != "" {
+		t.Errorf("Names after Delete (-want, +got):\n%s", diff)
+	}
+}
+
+func TestIsSchema(t *testing.T) {
+	tests := []struct {
+		schema string
+		want   bool
+	}{
+		{createTable, true},
+		{oldCreateTable, true},
+		{`CREATE TABLE moz_cookies (id INTEGER PRIMARY KEY, originAttributes TEXT NOT NULL DEFAULT '',
+  name TEXT, value TEXT, host TEXT, path TEXT, expiry INTEGER, lastAccessed INTEGER,
+  creationTime INTEGER, isSecure INTEGER, isHttpOnly INTEGER, inBrowserElement INTEGER DEFAULT 0,
+  sameSite INTEGER DEFAULT 0, rawSameSite INTEGER DEFAULT 0, schemeMap INTEGER DEFAULT 0)`, false},
+		{`CREATE TABLE moz_cookies (id INTEGER PRIMARY KEY, name TEXT)`, false},
+	}
+	for _, tc := range tests {
+		db, err := sql.Open("sqlite", ":memory:")
+		if err != nil {
+			t.Fatalf("Open database: %v", err)
+		}
+		db.SetMaxOpenConns(1) // each connection has its own in-memory database
+		if _, err := db.Exec(tc.schema); err != nil {
+			t.Fatalf("Create table: %v", err)
+		}
+		got, err := webkitgtk.IsSchema(db)
+		if err != nil {
+			t.Errorf("IsSchema: unexpected error: %v", err)
+		} else if got != tc.want {
+			t.Errorf("IsSchema(%q): got %v, want %v", tc.schema, got, tc.want)
+		}
+		db.Close()
+	}
+}
+
+func TestOpenDB(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("Open database: %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1) // each connection has its own in-memory database
+
+	// A database without a cookies table should be reported.
+	if s, err := webkitgtk.OpenDB(db, nil); err == nil {
+		t.Errorf("OpenDB without a table: got %v, want error", s)
+	}
+
+	if _, err := db.Exec(createTable); err != nil {
+		t.Fatalf("Create table: %v", err)
+	}
+	s, err := webkitgtk.OpenDB(db, nil)
+	if err != nil {
+		t.Fatalf("OpenDB: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+	if err := db.Ping(); err != nil {
+		t.Errorf("Ping after Close: %v", err)
+	}
+}
+
+func TestOldSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cookies.sqlite")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("Open test database: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(oldCreateTable); err != nil {
+		t.Fatalf("Create table: %v", err)
+	}
+	exp := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := db.Exec(`INSERT INTO moz_cookies VALUES(NULL, 'a', '1', '.example.com', '/', ?, NULL, 1, 0)`,
+		exp.Unix()); err != nil {
+		t.Fatalf("Insert cookie: %v", err)
+	}
+
+	s, err := webkitgtk.OpenDB(db, nil)
+	if err != nil {
+		t.Fatalf("OpenDB: %v", err)
+	}
+	defer s.Close()
+
+	// Cookies are read with an Unknown SameSite policy, and the policy of a
+	// cookie that is written is discarded.
+	if err := s.Put(cookies.C{Name: "b", Value: "2", Domain: "example.com", Path: "/", Expires: exp,
+		SameSite: cookies.Strict}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := s.Scan(func(e cookies.Editor) (cookies.Action, error) {
+		c := e.Get()
+		c.Value += "x"
+		c.SameSite = cookies.Lax
+		if err := e.Set(c); err != nil {
+			return 0, err
+		}
+		return cookies.Update, nil
+	}); err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if err := s.Put(cookies.C{Name: "b", Value: "3", Domain: "example.com", Path: "/", Expires: exp}); err != nil {
+		t.Fatalf("Put existing: %v", err)
+	}
+
+	var got []cookies.C
+	for c, err := range s.All() {
+		if err != nil {
+			t.Fatalf("All: unexpected error: %v", err)
+		}
+		got = append(got, c)
+	}
+	want := []cookies.C{
+		{Name: "a", Value: "1x", Domain: "example.com", Path: "/", Expires: exp,
+			Flags: cookies.Flags{Secure: true}},
+		{Name: "b", Value: "3", Domain: "example.com", Path: "/", Expires: exp},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Cookies (-want, +got):\n%s", diff)
+	}
+}